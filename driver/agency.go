@@ -0,0 +1,48 @@
+package opentelekomcloud
+
+import (
+	"fmt"
+
+	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack"
+)
+
+// agencyCloud assumes the agency and returns cloud configuration using agency-scoped token.
+// Project name of the cloud is used as delegated project name
+func (d *Driver) agencyCloud(cloud *openstack.Cloud, agencyName, agencyDomain string) (*openstack.Cloud, error) {
+	authInfo := cloud.AuthInfo
+	opts, err := openstack.AuthOptionsFromInfo(&authInfo, cloud.AuthType)
+	if err != nil {
+		return nil, err
+	}
+	delegatedProject := cloud.AuthInfo.ProjectName
+	switch o := opts.(type) {
+	case golangsdk.AuthOptions:
+		// initial token is scoped to user domain
+		o.TenantID = ""
+		o.TenantName = ""
+		o.AgencyName = agencyName
+		o.AgencyDomainName = agencyDomain
+		o.DelegatedProject = delegatedProject
+		opts = o
+	case golangsdk.AKSKAuthOptions:
+		o.ProjectId = ""
+		o.ProjectName = ""
+		o.AgencyName = agencyName
+		o.AgencyDomainName = agencyDomain
+		o.DelegatedProject = delegatedProject
+		opts = o
+	}
+	provider, err := d.newProviderClient(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assume agency `%s` of domain `%s`: %s", agencyName, agencyDomain, logHttp500(err))
+	}
+	tokenCloud := *cloud
+	tokenCloud.AuthType = "token"
+	tokenCloud.AuthInfo = openstack.AuthInfo{
+		AuthURL:   cloud.AuthInfo.AuthURL,
+		Token:     provider.Token(),
+		ProjectID: provider.ProjectID,
+	}
+	return &tokenCloud, nil
+}
@@ -0,0 +1,161 @@
+package opentelekomcloud
+
+import (
+	"fmt"
+
+	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v1/bandwidths"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v1/eips"
+)
+
+const (
+	bandwidthShareTypeShared = "WHOLE"
+	bandwidthStatusNormal    = "NORMAL"
+	// bandwidthChargeMode is charge mode of dedicated bandwidth assigned to EIP removed from shared bandwidth
+	bandwidthChargeMode = "bandwidth"
+)
+
+type publicIPInfo struct {
+	PublicIPID string `json:"publicip_id"`
+}
+
+// sharedBandwidthURL returns URL of shared bandwidth API, the API exists in VPC v2.0 only
+func (d *Driver) sharedBandwidthURL(parts ...string) string {
+	return d.network.ServiceURL(append([]string{d.network.ProjectID, "bandwidths"}, parts...)...)
+}
+
+// CreateSharedBandwidth creates shared bandwidth of given size (in MBit/s) and waits for it to be ready
+func (d *Driver) CreateSharedBandwidth(name string, size int) (string, error) {
+	body := map[string]interface{}{
+		"bandwidth": map[string]interface{}{"name": name, "size": size},
+	}
+	var result struct {
+		Bandwidth bandwidths.BandWidth `json:"bandwidth"`
+	}
+	_, err := d.network.Post(d.sharedBandwidthURL(), body, &result, &golangsdk.RequestOpts{
+		OkCodes: []int{200},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create shared bandwidth: %s", logHttp500(err))
+	}
+	bandwidthID := result.Bandwidth.ID
+	if err := d.WaitForSharedBandwidthStatus(bandwidthID, bandwidthStatusNormal); err != nil {
+		return bandwidthID, fmt.Errorf("failed to wait for shared bandwidth to be ready: %s", logHttp500(err))
+	}
+	return bandwidthID, nil
+}
+
+// DeleteSharedBandwidth deletes shared bandwidth, the bandwidth must contain no elastic IPs
+func (d *Driver) DeleteSharedBandwidth(bandwidthID string) error {
+	_, err := d.network.Delete(d.sharedBandwidthURL(bandwidthID), &golangsdk.RequestOpts{
+		OkCodes: []int{204},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete shared bandwidth: %s", logHttp500(err))
+	}
+	return nil
+}
+
+// FindSharedBandwidth returns ID of shared bandwidth with given name, empty string is returned if it's not found
+func (d *Driver) FindSharedBandwidth(name string) (string, error) {
+	bandwidthList, err := bandwidths.List(d.vpc, bandwidths.ListOpts{ShareType: bandwidthShareTypeShared}).Extract()
+	if err != nil {
+		return "", fmt.Errorf("failed to list shared bandwidths: %s", logHttp500(err))
+	}
+	for _, bandwidth := range bandwidthList {
+		if bandwidth.Name == name {
+			return bandwidth.ID, nil
+		}
+	}
+	return "", nil
+}
+
+// AddToSharedBandwidth adds elastic IPs to shared bandwidth
+func (d *Driver) AddToSharedBandwidth(bandwidthID string, eipIDs ...string) error {
+	body := map[string]interface{}{
+		"bandwidth": map[string]interface{}{"publicip_info": publicIPInfos(eipIDs)},
+	}
+	_, err := d.network.Post(d.sharedBandwidthURL(bandwidthID, "insert"), body, nil, &golangsdk.RequestOpts{
+		OkCodes: []int{200},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add elastic IP to shared bandwidth: %s", logHttp500(err))
+	}
+	return nil
+}
+
+// RemoveFromSharedBandwidth removes elastic IPs from shared bandwidth,
+// removed IPs get dedicated bandwidth of configured size
+func (d *Driver) RemoveFromSharedBandwidth(bandwidthID string, eipIDs ...string) error {
+	body := map[string]interface{}{
+		"bandwidth": map[string]interface{}{
+			"charge_mode":   bandwidthChargeMode,
+			"size":          d.eipConfig.BandwidthSize,
+			"publicip_info": publicIPInfos(eipIDs),
+		},
+	}
+	_, err := d.network.Post(d.sharedBandwidthURL(bandwidthID, "remove"), body, nil, &golangsdk.RequestOpts{
+		OkCodes: []int{204},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove elastic IP from shared bandwidth: %s", logHttp500(err))
+	}
+	return nil
+}
+
+func publicIPInfos(eipIDs []string) []publicIPInfo {
+	infos := make([]publicIPInfo, len(eipIDs))
+	for i, id := range eipIDs {
+		infos[i] = publicIPInfo{PublicIPID: id}
+	}
+	return infos
+}
+
+// validateSharedBandwidth checks that configured shared bandwidth exists
+func (d *Driver) validateSharedBandwidth() error {
+	if d.SharedBandwidthID == "" {
+		return nil
+	}
+	bandwidth, err := bandwidths.Get(d.vpc, d.SharedBandwidthID).Extract()
+	if err != nil {
+		return fmt.Errorf("failed to get shared bandwidth: %s", logHttp500(err))
+	}
+	if bandwidth.ShareType != bandwidthShareTypeShared {
+		return fmt.Errorf("bandwidth `%s` is not shared", d.SharedBandwidthID)
+	}
+	return nil
+}
+
+// createSharedElasticIP creates elastic IP using configured shared bandwidth
+func (d *Driver) createSharedElasticIP() (*eips.PublicIp, error) {
+	ipType := d.eipConfig.IPType
+	if ipType == "" {
+		ipType = "5_bgp"
+	}
+	eip, err := eips.Apply(d.vpc, eips.ApplyOpts{
+		IP: eips.PublicIpOpts{Type: ipType},
+		Bandwidth: eips.BandwidthOpts{
+			Id:        d.SharedBandwidthID,
+			ShareType: bandwidthShareTypeShared,
+		},
+	}).Extract()
+	if err != nil {
+		return nil, err
+	}
+	return &eip, nil
+}
+
+// leaveSharedBandwidth removes the machine elastic IP from the shared bandwidth, the bandwidth itself is kept
+func (d *Driver) leaveSharedBandwidth() error {
+	if d.SharedBandwidthID == "" || d.ElasticIP.Value == "" {
+		return nil
+	}
+	eip, err := d.findElasticIP(d.ElasticIP.Value)
+	if err != nil {
+		return fmt.Errorf("failed to find elastic IP: %s", logHttp500(err))
+	}
+	if eip == nil || eip.BandwidthID != d.SharedBandwidthID {
+		return nil
+	}
+	return d.RemoveFromSharedBandwidth(d.SharedBandwidthID, eip.ID)
+}
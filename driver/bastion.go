@@ -0,0 +1,122 @@
+package opentelekomcloud
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"strconv"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	defaultBastionPort = 22
+	localhost          = "127.0.0.1"
+)
+
+// bastionTunnel forwards local connections to the instance SSH port through the bastion host
+type bastionTunnel struct {
+	client   *ssh.Client
+	listener net.Listener
+	target   string
+	logger   Logger
+}
+
+// bastionConfig builds SSH client configuration for connecting to the bastion host.
+// Instance key is used if no bastion key is set
+func (d *Driver) bastionConfig() (*ssh.ClientConfig, error) {
+	keyFile := d.BastionKeyFile
+	if keyFile == "" {
+		keyFile = d.GetSSHKeyPath()
+	}
+	key, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bastion key: %s", err)
+	}
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bastion key: %s", err)
+	}
+	user := d.BastionUser
+	if user == "" {
+		user = d.GetSSHUsername()
+	}
+	return &ssh.ClientConfig{
+		User:            user,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	}, nil
+}
+
+// startBastionTunnel starts forwarding of local port to the instance private address,
+// tunnel is started once and lives as long as the driver process
+func (d *Driver) startBastionTunnel() (*bastionTunnel, error) {
+	if d.tunnel != nil {
+		return d.tunnel, nil
+	}
+	privateIP, err := d.instancePrivateIP()
+	if err != nil {
+		return nil, err
+	}
+	config, err := d.bastionConfig()
+	if err != nil {
+		return nil, err
+	}
+	port := d.BastionPort
+	if port == 0 {
+		port = defaultBastionPort
+	}
+	client, err := ssh.Dial("tcp", net.JoinHostPort(d.BastionHost, strconv.Itoa(port)), config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to bastion host: %s", err)
+	}
+	listener, err := net.Listen("tcp", net.JoinHostPort(localhost, "0"))
+	if err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to listen for bastion tunnel: %s", err)
+	}
+	d.tunnel = &bastionTunnel{
+		client:   client,
+		listener: listener,
+		target:   net.JoinHostPort(privateIP, strconv.Itoa(d.SSHPort)),
+		logger:   d.logger(),
+	}
+	go d.tunnel.serve()
+	return d.tunnel, nil
+}
+
+// port returns local port of the tunnel
+func (t *bastionTunnel) port() int {
+	return t.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (t *bastionTunnel) serve() {
+	for {
+		local, err := t.listener.Accept()
+		if err != nil {
+			return
+		}
+		go t.forward(local)
+	}
+}
+
+func (t *bastionTunnel) forward(local net.Conn) {
+	defer local.Close()
+	remote, err := t.client.Dial("tcp", t.target)
+	if err != nil {
+		orDefaultLogger(t.logger).Errorf("failed to connect to %s through bastion host: %s", t.target, err)
+		return
+	}
+	defer remote.Close()
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(remote, local)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(local, remote)
+		done <- struct{}{}
+	}()
+	<-done
+}
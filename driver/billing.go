@@ -0,0 +1,142 @@
+package opentelekomcloud
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/ecs/v1/cloudservers"
+)
+
+const (
+	billingModePostPaid = "postPaid"
+	billingModePrePaid  = "prePaid"
+
+	periodTypeMonth = "month"
+	periodTypeYear  = "year"
+)
+
+// maxPeriodNum contains maximum number of periods which can be ordered at once
+var maxPeriodNum = map[string]int{
+	periodTypeMonth: 9,
+	periodTypeYear:  3,
+}
+
+// order resource statuses, see "Querying Resources Contained in an Order" in BSS API docs
+const (
+	orderStatusSuccess = 1
+	orderStatusFailed  = 2
+)
+
+const (
+	bssAPIVersion = "v1.0"
+	// unsubscribeWithAttached unsubscribes the resource together with resources ordered with it (e.g. volumes)
+	unsubscribeWithAttached = 1
+)
+
+// validateBilling checks billing mode and prePaid period
+func (d *Driver) validateBilling() error {
+	switch d.BillingMode {
+	case "", billingModePostPaid:
+		return nil
+	case billingModePrePaid:
+	default:
+		return fmt.Errorf("billing mode must be one of `%s`, `%s`, got `%s`",
+			billingModePostPaid, billingModePrePaid, d.BillingMode)
+	}
+	maxNum, ok := maxPeriodNum[d.PeriodType]
+	if !ok {
+		return fmt.Errorf("period type must be one of `%s`, `%s`, got `%s`",
+			periodTypeMonth, periodTypeYear, d.PeriodType)
+	}
+	if d.PeriodNum < 1 || d.PeriodNum > maxNum {
+		return fmt.Errorf("number of periods must be in range 1-%d for `%s` period type, got %d",
+			maxNum, d.PeriodType, d.PeriodNum)
+	}
+	return nil
+}
+
+// prePaid reports if the instance is billed yearly/monthly
+func (d *Driver) prePaid() bool {
+	return d.BillingMode == billingModePrePaid
+}
+
+// extendParam returns charging info of the instance, nil is returned for pay-per-use instance
+func (d *Driver) extendParam() *cloudservers.ServerExtendParam {
+	if !d.prePaid() {
+		return nil
+	}
+	return &cloudservers.ServerExtendParam{
+		ChargingMode: billingModePrePaid,
+		PeriodType:   d.PeriodType,
+		PeriodNum:    d.PeriodNum,
+		IsAutoPay:    "true",
+	}
+}
+
+func (d *Driver) initBSS() error {
+	if d.bss != nil {
+		return nil
+	}
+	pc, err := d.providerClient()
+	if err != nil {
+		return err
+	}
+	bss, err := newBSSClient(pc, d.endpointOpts)
+	if err != nil {
+		return fmt.Errorf("failed to initialize BSS service: %s", logHttp500(err))
+	}
+	d.bss = bss
+	return nil
+}
+
+// newBSSClient creates client of Business Support System (BSS) handling orders of prePaid resources.
+// BSS resources belong to the domain, so domain ID is used in resource URLs instead of project ID
+func newBSSClient(pc *golangsdk.ProviderClient, eo golangsdk.EndpointOpts) (*golangsdk.ServiceClient, error) {
+	if pc.DomainID == "" {
+		return nil, fmt.Errorf("domain ID is not known for the authenticated user")
+	}
+	eo.ApplyDefaults("bss")
+	url, err := pc.EndpointLocator(eo)
+	if err != nil {
+		return nil, err
+	}
+	url = strings.TrimSuffix(url, "/")
+	if !strings.HasSuffix(url, "/"+bssAPIVersion) {
+		url += "/" + bssAPIVersion
+	}
+	return &golangsdk.ServiceClient{ProviderClient: pc, Endpoint: url + "/"}, nil
+}
+
+// createPrePaidInstance orders prePaid ECS instance and waits for the order to be completed.
+// ID of the ordered instance is returned together with the error if it's known, so the instance can be rolled back
+func (d *Driver) createPrePaidInstance(ctx context.Context, opts cloudservers.CreateOptsBuilder) (string, error) {
+	// BSS is initialized before ordering, otherwise the order can't be tracked
+	if err := d.initBSS(); err != nil {
+		return "", err
+	}
+	order, err := cloudservers.CreatePrePaid(d.ecs, opts).ExtractOrderResponse()
+	if err != nil {
+		return "", fmt.Errorf("failed to order ECS: %s", err)
+	}
+	id, err := d.waitForOrderSuccess(ctx, order.OrderID, ecsCreationTimeout)
+	if err != nil {
+		return id, fmt.Errorf("failed to wait for ECS order success: %s", err)
+	}
+	if id == "" {
+		return "", fmt.Errorf("server ID is not returned by ECS order %s", order.OrderID)
+	}
+	return id, nil
+}
+
+// unsubscribeInstance unsubscribes prePaid instance, prePaid instance can't be deleted directly
+func (d *Driver) unsubscribeInstance(instanceID string) error {
+	if err := d.initBSS(); err != nil {
+		return err
+	}
+	return cloudservers.DeleteOrder(d.bss, cloudservers.DeleteOrderOpts{
+		ResourceIds: []string{instanceID},
+		UnSubType:   unsubscribeWithAttached,
+	}).Err
+}
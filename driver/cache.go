@@ -0,0 +1,68 @@
+package opentelekomcloud
+
+import (
+	"fmt"
+	"sync"
+)
+
+// LookupCache stores IDs of flavors and images found by name, so machines created
+// in the same process don't repeat the same lookups. Only found IDs are cached
+type LookupCache struct {
+	mu      sync.Mutex
+	entries map[string]string
+}
+
+// NewLookupCache creates empty lookup cache
+func NewLookupCache() *LookupCache {
+	return &LookupCache{entries: map[string]string{}}
+}
+
+// lookupCache is shared by all drivers in the process
+var lookupCache = NewLookupCache()
+
+// InvalidateLookupCache removes all entries from the lookup cache shared by the drivers
+func InvalidateLookupCache() {
+	lookupCache.Invalidate()
+}
+
+// Invalidate removes all entries from the cache
+func (c *LookupCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[string]string{}
+}
+
+// lookup returns cached ID for the key or calls `find` and caches its non-empty result
+func (c *LookupCache) lookup(key string, find func() (string, error)) (string, error) {
+	c.mu.Lock()
+	id, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+	id, err := find()
+	if err != nil || id == "" {
+		return id, err
+	}
+	c.mu.Lock()
+	c.entries[key] = id
+	c.mu.Unlock()
+	return id, nil
+}
+
+// cachedLookup caches lookup of resource of given kind by name unless the cache is disabled.
+// Cache key includes region and project as private images are visible in the project only
+func (d *Driver) cachedLookup(kind, name string, find func() (string, error)) (string, error) {
+	if d.SkipLookupCache {
+		return find()
+	}
+	key := fmt.Sprintf("%s/%s/%s%s/%s", kind, d.Region, d.ProjectID, d.ProjectName, name)
+	return lookupCache.lookup(key, find)
+}
+
+// findFlavor resolves flavor ID by name
+func (d *Driver) findFlavor(name string) (string, error) {
+	return d.cachedLookup("flavor", name, func() (string, error) {
+		return d.client.FindFlavor(name)
+	})
+}
@@ -1,17 +1,80 @@
 package opentelekomcloud
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"regexp"
 	"strings"
+	"sync"
 
-	"github.com/docker/machine/libmachine/log"
+	"github.com/hashicorp/go-multierror"
 	"github.com/opentelekomcloud-infra/crutch-house/services"
 	"github.com/opentelekomcloud-infra/crutch-house/ssh"
 	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/common/tags"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/extensions/keypairs"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/extensions/schedulerhints"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/servers"
 	"github.com/opentelekomcloud/gophertelekomcloud/openstack/ecs/v1/cloudservers"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/imageservice/v2/images"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/kms/v1/keys"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/ports"
 )
 
+var uuidRe = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// ecsCreateOpts extends ECS creation options with IPv6, volume encryption and custom metadata support
+type ecsCreateOpts struct {
+	cloudservers.CreateOpts
+	IPv6Enable bool
+	KMSKeyID   string
+	Metadata   map[string]string
+	RootIOPS   int
+}
+
+func (opts ecsCreateOpts) ToServerCreateMap() (map[string]interface{}, error) {
+	b, err := opts.CreateOpts.ToServerCreateMap()
+	if err != nil {
+		return nil, err
+	}
+	server := b["server"].(map[string]interface{})
+	if opts.IPv6Enable {
+		for _, nic := range server["nics"].([]interface{}) {
+			nic.(map[string]interface{})["ipv6_enable"] = true
+		}
+	}
+	if opts.KMSKeyID != "" {
+		volumes := []interface{}{server["root_volume"]}
+		if dataVolumes, ok := server["data_volumes"].([]interface{}); ok {
+			volumes = append(volumes, dataVolumes...)
+		}
+		for _, volume := range volumes {
+			volume.(map[string]interface{})["metadata"] = map[string]string{
+				"__system__encrypted": "1",
+				"__system__cmkid":     opts.KMSKeyID,
+			}
+		}
+	}
+	if opts.RootIOPS > 0 {
+		server["root_volume"].(map[string]interface{})["iops"] = opts.RootIOPS
+	}
+	if len(opts.Metadata) > 0 {
+		metadata, ok := server["metadata"].(map[string]interface{})
+		if !ok {
+			metadata = make(map[string]interface{}, len(opts.Metadata))
+		}
+		for key, value := range opts.Metadata {
+			metadata[key] = value
+		}
+		server["metadata"] = metadata
+	}
+	return b, nil
+}
+
 func (d *Driver) initCompute() error {
 	if err := d.initComputeV1(); err != nil {
 		return err
@@ -20,94 +83,497 @@ func (d *Driver) initCompute() error {
 }
 
 func (d *Driver) initComputeV2() error {
+	if d.compute != nil {
+		return nil
+	}
 	if err := d.Authenticate(); err != nil {
 		return fmt.Errorf("failed to authenticate: %s", logHttp500(err))
 	}
 	if err := d.client.InitCompute(); err != nil {
 		return fmt.Errorf("failed to initialize Compute v2 service: %s", logHttp500(err))
 	}
+	compute, err := d.client.NewServiceClient("compute")
+	if err != nil {
+		return fmt.Errorf("failed to initialize Compute v2 service: %s", logHttp500(err))
+	}
+	d.compute = compute
 	return nil
 }
 
 func (d *Driver) initComputeV1() error {
+	if d.ecs != nil {
+		return nil
+	}
 	if err := d.Authenticate(); err != nil {
 		return fmt.Errorf("failed to authenticate: %s", logHttp500(err))
 	}
 	if err := d.client.InitECS(); err != nil {
-		return fmt.Errorf("failed to initialize Compute v2 service: %s", logHttp500(err))
+		return fmt.Errorf("failed to initialize Compute v1 service: %s", logHttp500(err))
+	}
+	ecs, err := d.client.NewServiceClient("ecs")
+	if err != nil {
+		return fmt.Errorf("failed to initialize Compute v1 service: %s", logHttp500(err))
+	}
+	d.ecs = ecs
+	return nil
+}
+
+func (d *Driver) initImage() error {
+	if err := d.Authenticate(); err != nil {
+		return fmt.Errorf("failed to authenticate: %s", logHttp500(err))
+	}
+	if d.image != nil {
+		return nil
+	}
+	image, err := d.client.NewServiceClient("image")
+	if err != nil {
+		return fmt.Errorf("failed to initialize Image v2 service: %s", logHttp500(err))
+	}
+	d.image = image
+	return nil
+}
+
+// getImage returns details of the image used for the instance
+func (d *Driver) getImage() (*images.Image, error) {
+	imageID := d.RootVolumeOpts.SourceID
+	if imageID == "" {
+		id, err := d.findImage(d.ImageName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find image by name: %s", logHttp500(err))
+		}
+		if id == "" {
+			return nil, fmt.Errorf(notFound, "image", d.ImageName)
+		}
+		imageID = id
+	}
+	image, err := images.Get(d.image, imageID).Extract()
+	if err != nil {
+		if _, ok := err.(golangsdk.ErrDefault404); ok {
+			return nil, fmt.Errorf(notFoundByID, "image", imageID)
+		}
+		return nil, fmt.Errorf("failed to get image details: %s", logHttp500(err))
+	}
+	return image, nil
+}
+
+// validateImageRequirements checks that root disk and flavor RAM are big enough for the image
+func (d *Driver) validateImageRequirements() error {
+	image, err := d.getImage()
+	if err != nil {
+		return err
+	}
+	flavor, err := d.findFlavorDetails()
+	if err != nil {
+		return err
+	}
+	return checkImageRequirements(image, flavor, d.RootVolumeOpts.Size, d.BootFromImage)
+}
+
+// checkImageRequirements checks image minimal disk and RAM requirements, disk of the flavor
+// is used as root disk for the instance booted from the image
+func checkImageRequirements(image *images.Image, flavor *Flavor, rootSize int, bootFromImage bool) error {
+	switch {
+	case bootFromImage && flavor.Disk > 0 && flavor.Disk < image.MinDiskGigabytes:
+		return fmt.Errorf("flavor `%s` disk size %dGB is less than minimal size %dGB required by image `%s`",
+			flavor.Name, flavor.Disk, image.MinDiskGigabytes, image.Name)
+	case !bootFromImage && rootSize < image.MinDiskGigabytes:
+		return fmt.Errorf("root volume size %dGB is less than minimal size %dGB required by image `%s`",
+			rootSize, image.MinDiskGigabytes, image.Name)
+	case flavor.RAM < image.MinRAMMegabytes:
+		return fmt.Errorf("flavor `%s` RAM size %dMB is less than minimal size %dMB required by image `%s`",
+			flavor.Name, flavor.RAM, image.MinRAMMegabytes, image.Name)
+	}
+	return nil
+}
+
+// validateKMSKey checks that KMS key used for volume encryption exists
+func (d *Driver) validateKMSKey() error {
+	if d.KMSKeyID == "" {
+		return nil
+	}
+	if !uuidRe.MatchString(d.KMSKeyID) {
+		return fmt.Errorf("invalid KMS key ID `%s`", d.KMSKeyID)
+	}
+	pc, err := d.providerClient()
+	if err != nil {
+		return err
+	}
+	kms, err := openstack.NewKMSV1(pc, d.endpointOpts)
+	if err != nil {
+		return fmt.Errorf("failed to initialize KMS service: %s", logHttp500(err))
+	}
+	if _, err := keys.Get(kms, d.KMSKeyID).ExtractKeyInfo(); err != nil {
+		return fmt.Errorf("failed to get KMS key `%s`: %s", d.KMSKeyID, logHttp500(err))
 	}
 	return nil
 }
 
 func (d *Driver) createInstance() error {
+	return d.CreateInstanceContext(context.Background())
+}
+
+// CreateInstanceContext creates ECS instance and waits for it to be running,
+// waiting is aborted when the context is done
+func (d *Driver) CreateInstanceContext(ctx context.Context) error {
 	if d.InstanceID != "" {
 		return nil
 	}
 	if err := d.initCompute(); err != nil {
 		return err
 	}
+	if d.ReuseExisting {
+		if err := d.adoptExistingInstance(); err != nil {
+			return err
+		}
+		if d.InstanceID != "" {
+			return d.WaitForInstanceStatusContext(ctx, d.InstanceID, services.InstanceStatusRunning)
+		}
+	}
+	if err := d.validateKMSKey(); err != nil {
+		return err
+	}
+	if err := d.getUserData(); err != nil {
+		return err
+	}
+	createInstance := d.createVolumeBootedInstance
+	if d.BootFromImage {
+		createInstance = d.createImageBootedInstance
+	}
+	id, err := createInstance(ctx)
+	// instance can be created even if the creation failed, so it's removed on rollback
+	d.InstanceID = id
+	if err != nil {
+		return err
+	}
+
+	if err := d.WaitForInstanceStatusContext(ctx, d.InstanceID, services.InstanceStatusRunning); err != nil {
+		d.logConsoleOutput(d.InstanceID)
+		return fmt.Errorf("failed to wait for instance status: %s", logHttp500(err))
+	}
+	if d.AutoRecovery {
+		// instance is usable without auto-recovery, so it's not a reason to fail the creation
+		if err := d.enableAutoRecovery(); err != nil {
+			d.logger().Warnf("Failed to enable auto-recovery of the instance: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// createVolumeBootedInstance creates ECS instance booted from the root volume created from the image
+func (d *Driver) createVolumeBootedInstance(ctx context.Context) (string, error) {
 	var secGroups []cloudservers.SecurityGroup
-	for _, sgID := range d.SecurityGroupIDs {
+	for _, sgID := range d.instanceSecurityGroups() {
 		secGroups = append(secGroups, cloudservers.SecurityGroup{ID: sgID})
 	}
-	if d.ManagedSecurityGroupID != "" {
-		secGroups = append(secGroups, cloudservers.SecurityGroup{ID: d.ManagedSecurityGroupID})
-	}
 
-	imageRef, err := d.client.FindImage(d.ImageName)
-	if err != nil {
-		return fmt.Errorf("failed to find image: %s", imageRef)
+	var dataVolumes []cloudservers.DataVolume
+	for _, disk := range d.DataVolumes {
+		dataVolumes = append(dataVolumes, cloudservers.DataVolume{
+			VolumeType: disk.Type,
+			Size:       disk.Size,
+		})
 	}
+
 	opts := cloudservers.CreateOpts{
-		ImageRef:  imageRef,
+		ImageRef:  d.RootVolumeOpts.SourceID,
 		FlavorRef: d.FlavorID,
-		Name:      d.MachineName,
+		Name:      d.instanceName(),
 		UserData:  d.UserData,
-		AdminPass: d.Password,
+		AdminPass: d.AdminPassword,
 		KeyName:   d.KeyPairName.Value,
 		VpcId:     d.VpcID.Value,
-		Nics: []cloudservers.Nic{
-			{SubnetId: d.SubnetID.Value},
-		},
-		Count: 1,
+		Nics:      d.instanceNics(),
+		Count:     1,
 		RootVolume: cloudservers.RootVolume{
 			VolumeType: d.RootVolumeOpts.Type,
 			Size:       d.RootVolumeOpts.Size,
 		},
+		DataVolumes:      dataVolumes,
 		SecurityGroups:   secGroups,
 		AvailabilityZone: d.AvailabilityZone,
-		SchedulerHints: &cloudservers.SchedulerHints{
-			Group: d.ServerGroupID,
-		},
-		Tags: d.Tags,
+		SchedulerHints:   d.schedulerHints(),
+		ServerTags:       d.serverTags(),
+		ExtendParam:      d.extendParam(),
+	}
+	if d.BindEIPOnCreate {
+		opts.PublicIp = d.instancePublicIP()
 	}
 
-	id, err := d.client.CreateECSInstance(opts, 600)
+	id, err := d.createECSInstance(ctx, ecsCreateOpts{
+		CreateOpts: opts,
+		IPv6Enable: d.IPv6,
+		KMSKeyID:   d.KMSKeyID,
+		Metadata:   d.Metadata,
+		RootIOPS:   d.RootVolumeIOPS,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create compute v1 instance: %s", logHttp500(err))
+		if id != "" {
+			if rbErr := d.rollbackInstance(id); rbErr != nil {
+				d.logger().Errorf("failed to roll back instance %s: %s", id, rbErr)
+				// the instance is left for removal together with other machine resources
+				return id, fmt.Errorf("failed to create compute v1 instance: %s", logHttp500(err))
+			}
+		}
+		return "", fmt.Errorf("failed to create compute v1 instance: %s", logHttp500(err))
 	}
-	d.InstanceID = id
+	d.eipBoundOnCreate = opts.PublicIp != nil
+	return id, nil
+}
 
-	if err := d.client.WaitForInstanceStatus(d.InstanceID, services.InstanceStatusRunning); err != nil {
-		return fmt.Errorf("failed to wait for instance status: %s", logHttp500(err))
+// schedulerHints returns scheduler hints placing the instance to the server group and the dedicated host
+func (d *Driver) schedulerHints() *cloudservers.SchedulerHints {
+	hints := &cloudservers.SchedulerHints{Group: d.ServerGroupID.Value}
+	if d.DedicatedHostID != "" {
+		hints.Tenancy = tenancyDedicated
+		hints.DedicatedHostID = d.DedicatedHostID
+	}
+	return hints
+}
+
+// createImageBootedInstance creates instance booted from the image directly, instance root disk
+// is not a persistent volume and is removed together with the instance
+func (d *Driver) createImageBootedInstance(_ context.Context) (string, error) {
+	var networks []servers.Network
+	for _, nic := range d.instanceNics() {
+		networks = append(networks, servers.Network{UUID: nic.SubnetId, FixedIP: nic.IpAddress})
+	}
+	var opts servers.CreateOptsBuilder = servers.CreateOpts{
+		Name:             d.instanceName(),
+		ImageRef:         d.RootVolumeOpts.SourceID,
+		FlavorRef:        d.FlavorID,
+		SecurityGroups:   d.instanceSecurityGroups(),
+		UserData:         d.UserData,
+		AvailabilityZone: d.AvailabilityZone,
+		Networks:         networks,
+		Metadata:         d.Metadata,
+		AdminPass:        d.AdminPassword,
+	}
+	if d.KeyPairName.Value != "" {
+		opts = keypairs.CreateOptsExt{CreateOptsBuilder: opts, KeyName: d.KeyPairName.Value}
+	}
+	if d.ServerGroupID.Value != "" {
+		opts = schedulerhints.CreateOptsExt{
+			CreateOptsBuilder: opts,
+			SchedulerHints:    schedulerhints.SchedulerHints{Group: d.ServerGroupID.Value},
+		}
+	}
+	server, err := servers.Create(d.compute, opts).Extract()
+	if err != nil {
+		return "", fmt.Errorf("failed to create compute v2 instance: %s", logHttp500(err))
+	}
+	// compute v2 API doesn't support tags on creation
+	if len(d.Tags) > 0 {
+		if err := tags.Create(d.ecs, "cloudservers", server.ID, d.Tags).ExtractErr(); err != nil {
+			return server.ID, fmt.Errorf("failed to tag instance `%s`: %s", server.ID, logHttp500(err))
+		}
+	}
+	return server.ID, nil
+}
+
+// enableAutoRecovery enables auto-recovery of the instance if it's supported by the instance flavor
+func (d *Driver) enableAutoRecovery() error {
+	flavors, err := d.ListFlavors(d.AvailabilityZone)
+	if err != nil {
+		return err
+	}
+	for _, flavor := range flavors {
+		if flavor.ID != d.FlavorID {
+			continue
+		}
+		if !flavor.supportsAutoRecovery() {
+			d.logger().Warnf("Flavor `%s` doesn't support auto-recovery, it won't be enabled", flavor.Name)
+			return nil
+		}
+		break
+	}
+	body := map[string]string{"support_auto_recovery": "true"}
+	recoveryURL := d.ecs.ServiceURL("cloudservers", d.InstanceID, "autorecovery")
+	if _, err := d.ecs.Put(recoveryURL, body, nil, &golangsdk.RequestOpts{
+		OkCodes: []int{204},
+	}); err != nil {
+		return fmt.Errorf("failed to enable instance auto-recovery: %s", logHttp500(err))
+	}
+	return nil
+}
+
+// adoptExistingInstance uses existing instance with the machine name instead of creating new one.
+// Stopped instance is started, instance in error status is removed to be created again
+func (d *Driver) adoptExistingInstance() error {
+	instanceID, err := d.findInstance(d.instanceName())
+	if err != nil {
+		return fmt.Errorf("failed to find existing instance: %s", logHttp500(err))
+	}
+	if instanceID == "" {
+		return nil
+	}
+	instance, err := d.GetInstanceStatus(instanceID)
+	if err != nil {
+		return fmt.Errorf("failed to get existing instance status: %s", logHttp500(err))
+	}
+	d.InstanceID = instanceID
+	switch instance.Status {
+	case instanceStatusError:
+		d.logger().Warnf("Existing instance `%s` is in error status, recreating it", instanceID)
+		if err := d.deleteInstance(); err != nil {
+			return err
+		}
+		d.InstanceID = ""
+		return nil
+	case services.InstanceStatusStopped:
+		if err := d.client.StartInstance(instanceID); err != nil {
+			return fmt.Errorf("failed to start existing instance: %s", logHttp500(err))
+		}
+	}
+	d.logger().Infof("Reusing existing instance `%s`", instanceID)
+	return d.loadAdoptedState()
+}
+
+// loadAdoptedState populates the driver state from the adopted instance. Resources used by the instance
+// (elastic IP, network, security groups, key pair) are not created by the driver, so they aren't managed
+func (d *Driver) loadAdoptedState() error {
+	server, err := d.client.GetECSStatus(d.InstanceID)
+	if err != nil {
+		return fmt.Errorf("failed to get existing instance details: %s", logHttp500(err))
+	}
+	if d.KeyPairName.Value != "" && server.KeyName != d.KeyPairName.Value {
+		return fmt.Errorf("existing instance `%s` uses key pair `%s` instead of configured `%s`",
+			d.InstanceID, server.KeyName, d.KeyPairName.Value)
 	}
+	d.FlavorID = server.Flavor.ID
+	d.FlavorName = server.Flavor.Name
+	d.AvailabilityZone = server.AvailabilityZone
+	d.KeyPairName = managedSting{Value: server.KeyName}
+	if server.KeyName != "" && d.PrivateKeyFile == "" {
+		keyPath := d.GetSSHKeyPath()
+		if _, err := os.Stat(keyPath); err != nil {
+			return fmt.Errorf("private key of key pair `%s` used by existing instance is not found: %s", server.KeyName, err)
+		}
+		d.PrivateKeyFile = keyPath
+	}
+
+	var groups []string
+	for _, group := range server.SecurityGroups {
+		groups = append(groups, group.Name)
+	}
+	d.SecurityGroups = groups
+	d.SecurityGroupIDs = nil
+	d.ManagedSecurityGroupID = ""
 
+	if server.Metadata.VpcID != "" {
+		d.VpcID = managedSting{Value: server.Metadata.VpcID}
+	}
+	var portID string
+	for _, addresses := range server.Addresses {
+		for _, addr := range addresses {
+			switch {
+			case addr.Type == addressTypeFloating:
+				d.ElasticIP = managedSting{Value: addr.Addr}
+				d.eipBoundOnCreate = true
+			case addr.Version == "4" && portID == "":
+				portID = addr.PortID
+				d.PrivateIP = addr.Addr
+			}
+		}
+	}
+	if portID != "" {
+		if err := d.initNetwork(); err != nil {
+			return err
+		}
+		port, err := ports.Get(d.network, portID).Extract()
+		if err != nil {
+			return fmt.Errorf("failed to get port of existing instance: %s", logHttp500(err))
+		}
+		d.SubnetID = managedSting{Value: port.NetworkID}
+	}
+	return nil
+}
+
+// instanceNics returns instance NICs, NIC in the primary subnet goes first
+func (d *Driver) instanceNics() []cloudservers.Nic {
+	nics := []cloudservers.Nic{{SubnetId: d.SubnetID.Value, IpAddress: d.PrivateIP}}
+	for _, subnetID := range d.AdditionalSubnetIDs {
+		nics = append(nics, cloudservers.Nic{SubnetId: subnetID})
+	}
+	return nics
+}
+
+// ecsCreationTimeout is a timeout of ECS creation job (in seconds)
+const ecsCreationTimeout = 600
+
+// createECSInstance starts ECS creation job and returns ID of created server once job succeeds
+func (d *Driver) createECSInstance(ctx context.Context, opts cloudservers.CreateOptsBuilder) (string, error) {
+	if d.prePaid() {
+		return d.createPrePaidInstance(ctx, opts)
+	}
+	job, err := cloudservers.Create(d.ecs, opts).ExtractJobResponse()
+	if err != nil {
+		return "", fmt.Errorf("failed to create ECS: %s", err)
+	}
+	if err := d.waitForJobSuccess(ctx, job.JobID, ecsCreationTimeout); err != nil {
+		// server can be created even if the job fails, its ID is returned for the rollback
+		id, _ := d.jobServerID(job.JobID)
+		return id, fmt.Errorf("failed to wait for ECS creation success: %s", err)
+	}
+	id, err := d.jobServerID(job.JobID)
+	if err != nil {
+		return "", err
+	}
+	if id == "" {
+		return "", fmt.Errorf("server ID is not returned by ECS creation job %s", job.JobID)
+	}
+	return id, nil
+}
+
+// jobServerID returns ID of the server created by the job, empty string is returned if no server is created
+func (d *Driver) jobServerID(jobID string) (string, error) {
+	job := new(cloudservers.JobStatus)
+	if _, err := d.ecs.Get(d.ecs.ServiceURL("jobs", jobID), job, nil); err != nil {
+		return "", fmt.Errorf("failed to get job entity: %s", err)
+	}
+	for _, subJob := range job.Entities.SubJobs {
+		if id := subJob.Entities["server_id"]; id != "" {
+			return id, nil
+		}
+	}
+	return "", nil
+}
+
+// rollbackInstance removes partially created instance together with attached volumes
+func (d *Driver) rollbackInstance(instanceID string) error {
+	return d.deleteAndWaitInstance(instanceID, func() error {
+		if d.prePaid() {
+			return d.unsubscribeInstance(instanceID)
+		}
+		return d.client.DeleteECSInstance(instanceID)
+	})
+}
+
+// deleteAndWaitInstance deletes the instance using given delete function and waits until the instance is not found
+func (d *Driver) deleteAndWaitInstance(instanceID string, deleteFunc func() error) error {
+	if err := deleteFunc(); err != nil {
+		return fmt.Errorf("failed to delete instance: %s", logHttp500(err))
+	}
+	err := d.waitForInstanceStatus(instanceID, "")
+	if !errors.Is(err, ErrInstanceNotFound) {
+		return fmt.Errorf("failed to wait for instance status after deletion: %s", logHttp500(err))
+	}
 	return nil
 }
 
 func (d *Driver) loadSSHKey() error {
-	log.Debug("Loading Key Pair", d.KeyPairName.Value)
+	d.logger().Debugf("Loading key pair `%s`", d.KeyPairName.Value)
 	if err := d.initComputeV2(); err != nil {
 		return err
 	}
-	log.Debug("Loading Private Key from", d.PrivateKeyFile)
+	d.logger().Debugf("Loading private key from %s", d.PrivateKeyFile)
 	privateKey, err := ioutil.ReadFile(d.PrivateKeyFile)
 	if err != nil {
 		return fmt.Errorf("failed to read private key: %s", err)
 	}
-	publicKey, err := d.client.GetPublicKey(d.KeyPairName.Value)
+	publicKey, err := d.keyPairPublicKey(privateKey)
 	if err != nil {
-		return fmt.Errorf("failed to get public key: %s", logHttp500(err))
+		return err
 	}
 	privateKeyPath := d.GetSSHKeyPath()
 	if err := ioutil.WriteFile(privateKeyPath, privateKey, 0600); err != nil {
@@ -122,7 +588,16 @@ func (d *Driver) loadSSHKey() error {
 
 func (d *Driver) createSSHKey() error {
 	d.KeyPairName.Value = strings.Replace(d.KeyPairName.Value, ".", "_", -1)
-	log.Debug("Creating Key Pair...", map[string]string{"Name": d.KeyPairName.Value})
+	d.logger().Debugf("Creating key pair `%s`", d.KeyPairName.Value)
+	d.KeyPairName = managedSting{d.KeyPairName.Value, true}
+	if err := d.initComputeV2(); err != nil {
+		return err
+	}
+	if d.CloudGeneratedKey {
+		// key pair is generated by OTC from empty public key
+		_, err := d.createKeyPair(nil)
+		return err
+	}
 	keyPath := d.GetSSHKeyPath()
 	if err := ssh.GenerateSSHKey(keyPath); err != nil {
 		return err
@@ -132,36 +607,240 @@ func (d *Driver) createSSHKey() error {
 	if err != nil {
 		return fmt.Errorf("failed to read public key file: %s", err)
 	}
-	d.KeyPairName = managedSting{d.KeyPairName.Value, true}
-	if err := d.initComputeV2(); err != nil {
+	if _, err := d.createKeyPair(publicKey); err != nil {
 		return err
 	}
-	if _, err := d.createKeyPair(publicKey); err != nil {
+	return nil
+}
+
+// createLocalSSHKey generates machine SSH key without creating key pair, public key is added
+// to authorized keys of the instance user via cloud-init user data
+func (d *Driver) createLocalSSHKey() error {
+	keyPath := d.GetSSHKeyPath()
+	if err := ssh.GenerateSSHKey(keyPath); err != nil {
 		return err
 	}
+	d.PrivateKeyFile = keyPath
+	publicKey, err := ioutil.ReadFile(keyPath + ".pub")
+	if err != nil {
+		return fmt.Errorf("failed to read public key file: %s", err)
+	}
+	d.UserData = []byte(fmt.Sprintf("#cloud-config\nssh_authorized_keys:\n  - %s\n", strings.TrimSpace(string(publicKey))))
 	return nil
 }
 
+// createKeyPair creates key pair with given public key. If the public key is empty, the key pair
+// is generated by OTC and returned private key is written to the machine SSH key path
 func (d *Driver) createKeyPair(publicKey []byte) (string, error) {
 	kp, err := d.client.CreateKeyPair(d.KeyPairName.Value, string(publicKey))
 	if err != nil {
 		return "", fmt.Errorf("failed to create key pair: %s", logHttp500(err))
 	}
+	if len(publicKey) == 0 {
+		if kp.PrivateKey == "" {
+			return "", fmt.Errorf("private key of generated key pair `%s` is not returned", d.KeyPairName.Value)
+		}
+		if err := d.writeSSHKey([]byte(kp.PrivateKey), []byte(kp.PublicKey)); err != nil {
+			return "", err
+		}
+	}
 	return kp.PublicKey, nil
 }
 
+// writeSSHKey writes the key to the machine SSH key path readable by the owner only
+func (d *Driver) writeSSHKey(privateKey, publicKey []byte) error {
+	keyPath := d.GetSSHKeyPath()
+	for path, data := range map[string][]byte{keyPath: privateKey, keyPath + ".pub": publicKey} {
+		if err := ioutil.WriteFile(path, data, 0600); err != nil {
+			return fmt.Errorf("failed to write key file: %s", err)
+		}
+		// WriteFile doesn't change permissions of existing file
+		if err := os.Chmod(path, 0600); err != nil {
+			return fmt.Errorf("failed to set key file permissions: %s", err)
+		}
+	}
+	d.PrivateKeyFile = keyPath
+	return nil
+}
+
 func (d *Driver) deleteInstance() error {
 	if err := d.initComputeV2(); err != nil {
 		return err
 	}
-	if err := d.client.DeleteInstance(d.InstanceID); err != nil {
-		return fmt.Errorf("failed to delete instance: %s", logHttp500(err))
+	// instance booted from the image has no volumes created by the driver
+	keepVolumes := d.KeepVolumesOnRemove && !d.BootFromImage
+	if keepVolumes {
+		if err := d.keepInstanceVolumes(); err != nil {
+			return err
+		}
 	}
-	err := d.client.WaitForInstanceStatus(d.InstanceID, "")
-	switch err.(type) {
-	case golangsdk.ErrDefault404:
-	default:
-		return fmt.Errorf("failed to wait for instance status after deletion: %s", logHttp500(err))
+	return d.deleteAndWaitInstance(d.InstanceID, func() error {
+		if d.prePaid() {
+			return d.unsubscribeInstance(d.InstanceID)
+		}
+		if !keepVolumes && len(d.DataVolumes) > 0 {
+			// ECS deletion removes attached data volumes as well
+			return d.client.DeleteECSInstance(d.InstanceID)
+		}
+		return d.client.DeleteInstance(d.InstanceID)
+	})
+}
+
+const (
+	stopTypeSoft = "SOFT"
+	stopTypeHard = "HARD"
+)
+
+// requestStop requests stop of the instance without waiting for it
+func (d *Driver) requestStop(instanceID, stopType string) error {
+	body := map[string]interface{}{
+		"os-stop": map[string]interface{}{
+			"type":    stopType,
+			"servers": []map[string]string{{"id": instanceID}},
+		},
+	}
+	if _, err := d.ecs.Post(d.ecs.ServiceURL("cloudservers", "action"), body, nil, &golangsdk.RequestOpts{
+		OkCodes: []int{200},
+	}); err != nil {
+		return fmt.Errorf("failed to stop instance: %s", logHttp500(err))
+	}
+	return nil
+}
+
+// DeleteInstanceForce deletes hung instance: hard stop is requested without waiting for it
+// and the instance is deleted right away. Deletion is still waited for
+func (d *Driver) DeleteInstanceForce(instanceID string) error {
+	if err := d.initCompute(); err != nil {
+		return err
+	}
+	if err := d.requestStop(instanceID, stopTypeHard); err != nil {
+		// instance in error status can't be stopped, but can be deleted
+		d.logger().Debugf("Hard stop of instance `%s` failed: %s", instanceID, err)
+	}
+	return d.deleteAndWaitInstance(instanceID, func() error {
+		return servers.Delete(d.compute, instanceID).ExtractErr()
+	})
+}
+
+// deleteConcurrency limits number of instances deleted by DeleteInstances at the same time
+const deleteConcurrency = 10
+
+// DeleteInstances deletes instances with given IDs concurrently and waits for all of them to be deleted.
+// Instances which are already deleted are skipped. Errors of all failed instances are aggregated
+func (d *Driver) DeleteInstances(ids []string) error {
+	if err := d.initComputeV2(); err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, deleteConcurrency)
+	errs := make([]error, len(ids))
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			errs[i] = d.deleteInstanceByID(id)
+		}(i, id)
+	}
+	wg.Wait()
+
+	var result *multierror.Error
+	for i, err := range errs {
+		if err != nil {
+			result = multierror.Append(result, fmt.Errorf("instance `%s`: %s", ids[i], err))
+		}
+	}
+	return result.ErrorOrNil()
+}
+
+// deleteInstanceByID deletes single instance and waits for it to be deleted
+func (d *Driver) deleteInstanceByID(instanceID string) error {
+	return d.deleteAndWaitInstance(instanceID, func() error {
+		err := servers.Delete(d.compute, instanceID).ExtractErr()
+		if _, ok := err.(golangsdk.ErrDefault404); ok {
+			// already deleted instance is not found by the waiting right away
+			return nil
+		}
+		return err
+	})
+}
+
+// StopInstance stops the instance and waits for it to be stopped,
+// forced (hard) stop is done if `force` is set, graceful (soft) stop otherwise
+func (d *Driver) StopInstance(force bool) error {
+	if err := d.initCompute(); err != nil {
+		return err
+	}
+	stopType := stopTypeSoft
+	if force {
+		stopType = stopTypeHard
+	}
+	if err := d.requestStop(d.InstanceID, stopType); err != nil {
+		return err
+	}
+	if err := d.waitForInstanceStatus(d.InstanceID, services.InstanceStatusStopped); err != nil {
+		return fmt.Errorf("failed to wait for instance status: %s", logHttp500(err))
+	}
+	return nil
+}
+
+// RestartInstance reboots the instance and waits for it to be running again,
+// hard reboot is done if `hard` is set, soft reboot otherwise
+func (d *Driver) RestartInstance(hard bool) error {
+	if err := d.initComputeV2(); err != nil {
+		return err
+	}
+	rebootType := servers.SoftReboot
+	if hard {
+		rebootType = servers.HardReboot
+	}
+	if err := servers.Reboot(d.compute, d.InstanceID, servers.RebootOpts{Type: rebootType}).Err; err != nil {
+		return fmt.Errorf("failed to restart instance: %s", logHttp500(err))
+	}
+	if err := d.waitForInstanceStatus(d.InstanceID, services.InstanceStatusRunning); err != nil {
+		return fmt.Errorf("failed to wait for instance status: %s", logHttp500(err))
+	}
+	return nil
+}
+
+// ResizeInstance changes flavor of the existing instance
+func (d *Driver) ResizeInstance(flavorName string) error {
+	if err := d.initComputeV2(); err != nil {
+		return err
+	}
+	flavorID, err := d.findFlavor(flavorName)
+	if err != nil {
+		return fmt.Errorf("fail when searching flavor by name: %s", logHttp500(err))
+	}
+	if flavorID == "" {
+		return fmt.Errorf(notFound, "flavor", flavorName)
+	}
+	if d.AvailabilityZone != "" {
+		available, err := d.flavorInZone(flavorID, d.AvailabilityZone)
+		if err != nil {
+			return err
+		}
+		if !available {
+			return fmt.Errorf("flavor `%s` is not available in availability zone `%s`", flavorName, d.AvailabilityZone)
+		}
+	}
+	if err := servers.Resize(d.compute, d.InstanceID, servers.ResizeOpts{FlavorRef: flavorID}).Err; err != nil {
+		return fmt.Errorf("failed to resize instance: %s", logHttp500(err))
+	}
+	if err := d.waitForInstanceStatus(d.InstanceID, instanceStatusVerifyResize); err != nil {
+		if rErr := servers.RevertResize(d.compute, d.InstanceID).Err; rErr != nil {
+			d.logger().Errorf("failed to revert instance resize: %s", logHttp500(rErr))
+		}
+		return fmt.Errorf("failed to wait for instance resize: %s", logHttp500(err))
+	}
+	if err := servers.ConfirmResize(d.compute, d.InstanceID).Err; err != nil {
+		return fmt.Errorf("failed to confirm instance resize: %s", logHttp500(err))
+	}
+	if err := d.waitForInstanceStatus(d.InstanceID, services.InstanceStatusRunning); err != nil {
+		return fmt.Errorf("failed to wait for instance status: %s", logHttp500(err))
 	}
+	d.FlavorID = flavorID
+	d.FlavorName = flavorName
 	return nil
 }
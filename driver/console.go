@@ -0,0 +1,80 @@
+package opentelekomcloud
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/servers"
+)
+
+const (
+	// consoleTailLength is a number of console log lines logged when instance fails to start
+	consoleTailLength = 30
+
+	consoleTypeNoVNC = "novnc"
+)
+
+// supportedConsoleTypes maps remote console types to their protocols
+var supportedConsoleTypes = map[string]string{
+	consoleTypeNoVNC: "vnc",
+}
+
+// GetConsoleOutput returns last `length` lines of instance console log (whole log if `length` is not positive).
+// Empty string is returned if the log is not available yet, e.g. for just booted instance
+func (d *Driver) GetConsoleOutput(instanceID string, length int) (string, error) {
+	if err := d.initComputeV2(); err != nil {
+		return "", err
+	}
+	if length < 0 {
+		length = 0
+	}
+	output, err := servers.ShowConsoleOutput(d.compute, instanceID, servers.ShowConsoleOutputOpts{
+		Length: length,
+	}).Extract()
+	if err != nil {
+		return "", fmt.Errorf("failed to get console output: %s", logHttp500(err))
+	}
+	return strings.TrimRight(output, "\n"), nil
+}
+
+// logConsoleOutput logs console log tail for debugging of instance boot failures
+func (d *Driver) logConsoleOutput(instanceID string) {
+	output, err := d.GetConsoleOutput(instanceID, consoleTailLength)
+	if err != nil {
+		d.logger().Debugf("%s", err)
+		return
+	}
+	if output == "" {
+		d.logger().Debugf("Console log of instance %s is empty", instanceID)
+		return
+	}
+	d.logger().Debugf("Last lines of instance %s console log:\n%s", instanceID, output)
+}
+
+// GetConsoleURL returns URL of instance remote console of given type (only `novnc` is supported).
+// The URL is valid for a short time only, so it should be requested right before opening the console
+func (d *Driver) GetConsoleURL(instanceID, consoleType string) (string, error) {
+	protocol, ok := supportedConsoleTypes[consoleType]
+	if !ok {
+		return "", fmt.Errorf("unsupported console type `%s`, only `%s` is supported", consoleType, consoleTypeNoVNC)
+	}
+	if err := d.initComputeV1(); err != nil {
+		return "", err
+	}
+	body := map[string]interface{}{
+		"remote_console": map[string]string{
+			"protocol": protocol,
+			"type":     consoleType,
+		},
+	}
+	var result struct {
+		Console struct {
+			URL string `json:"url"`
+		} `json:"remote_console"`
+	}
+	consoleURL := d.ecs.ServiceURL("cloudservers", instanceID, "remote_console")
+	if _, err := d.ecs.Post(consoleURL, body, &result, nil); err != nil {
+		return "", fmt.Errorf("failed to get remote console URL: %s", logHttp500(err))
+	}
+	return result.Console.URL, nil
+}
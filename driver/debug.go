@@ -0,0 +1,78 @@
+package opentelekomcloud
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+)
+
+// maxDebugBodySize limits size of request and response bodies written to the debug log
+const maxDebugBodySize = 8192
+
+// redactedHeaders are headers containing credentials
+var redactedHeaders = []string{"Authorization", "X-Auth-Token", "X-Subject-Token", "X-Security-Token"}
+
+// secretFieldRe matches JSON string fields containing credentials, e.g. `password`, `adminPass`
+// or `private_key` of generated key pair
+var secretFieldRe = regexp.MustCompile(`(?i)("[a-z_]*(?:password|pass|secret|user_data|private_key)[a-z_]*"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+// tokenIDRe matches `id` field of `token` object, it contains the token in token auth request
+var tokenIDRe = regexp.MustCompile(`("token"\s*:\s*\{[^{}]*?"id"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+const redacted = "***"
+
+// redactBody replaces values of credential fields in JSON body
+func redactBody(body []byte) string {
+	result := secretFieldRe.ReplaceAll(body, []byte(`$1"`+redacted+`"`))
+	result = tokenIDRe.ReplaceAll(result, []byte(`$1"`+redacted+`"`))
+	if len(result) > maxDebugBodySize {
+		return string(result[:maxDebugBodySize]) + "... (truncated)"
+	}
+	return string(result)
+}
+
+// redactHeaders returns copy of headers with credential values replaced
+func redactHeaders(header http.Header) http.Header {
+	result := header.Clone()
+	for _, name := range redactedHeaders {
+		if result.Get(name) != "" {
+			result.Set(name, redacted)
+		}
+	}
+	return result
+}
+
+// debugTransport writes API requests and responses to the debug log with credentials redacted
+type debugTransport struct {
+	base   http.RoundTripper
+	logger Logger
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	logger := orDefaultLogger(t.logger)
+	var reqBody []byte
+	if req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			reqBody, _ = ioutil.ReadAll(body)
+			_ = body.Close()
+		}
+	}
+	logger.Debugf("API request: %s %s\nHeaders: %v\nBody: %s",
+		req.Method, req.URL, redactHeaders(req.Header), redactBody(reqBody))
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		logger.Debugf("API request %s %s failed: %s", req.Method, req.URL, err)
+		return resp, err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+	if err != nil {
+		return resp, err
+	}
+	logger.Debugf("API response: %s %s: %d\nHeaders: %v\nBody: %s",
+		req.Method, req.URL, resp.StatusCode, redactHeaders(resp.Header), redactBody(respBody))
+	return resp, nil
+}
@@ -0,0 +1,98 @@
+package opentelekomcloud
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/deh/v1/hosts"
+)
+
+const (
+	tenancyDedicated       = "dedicated"
+	dedicatedHostAvailable = "available"
+)
+
+func (d *Driver) initDeH() error {
+	if d.deh != nil {
+		return nil
+	}
+	pc, err := d.providerClient()
+	if err != nil {
+		return err
+	}
+	deh, err := openstack.NewDeHServiceV1(pc, d.endpointOpts)
+	if err != nil {
+		return fmt.Errorf("failed to initialize DeH service: %s", logHttp500(err))
+	}
+	d.deh = deh
+	return nil
+}
+
+// GetDedicatedHost returns details of the dedicated host, ErrDedicatedHostNotFound is returned if the host doesn't exist
+func (d *Driver) GetDedicatedHost(hostID string) (*hosts.Host, error) {
+	if err := d.initDeH(); err != nil {
+		return nil, err
+	}
+	host, err := hosts.Get(d.deh, hostID).Extract()
+	if err != nil {
+		return nil, wrapNotFound(err, ErrDedicatedHostNotFound)
+	}
+	return host, nil
+}
+
+// validateDedicatedHost checks that the dedicated host is available and has enough capacity for the flavor.
+// Availability zone of the host is used for the instance if no zone is configured
+func (d *Driver) validateDedicatedHost() error {
+	if d.DedicatedHostID == "" {
+		return nil
+	}
+	host, err := d.GetDedicatedHost(d.DedicatedHostID)
+	if err != nil {
+		return fmt.Errorf("failed to get dedicated host: %w", logHttp500(err))
+	}
+	if host.State != dedicatedHostAvailable {
+		return fmt.Errorf("dedicated host `%s` is in `%s` state", host.ID, host.State)
+	}
+	flavor, err := d.findFlavorDetails()
+	if err != nil {
+		return err
+	}
+	if err := checkDedicatedHostCapacity(host, flavor); err != nil {
+		return err
+	}
+	switch {
+	case d.AvailabilityZone == "" && len(d.AvailabilityZones) == 0:
+		d.AvailabilityZone = host.Az
+		d.logger().Infof("Availability zone `%s` of the dedicated host is selected for the machine", host.Az)
+	case d.AvailabilityZone != "" && d.AvailabilityZone != host.Az:
+		return fmt.Errorf("dedicated host `%s` is in availability zone `%s`, not in `%s`",
+			host.ID, host.Az, d.AvailabilityZone)
+	case len(d.AvailabilityZones) > 0:
+		return fmt.Errorf(errorExclusiveOptions, "DedicatedHostID", "AvailabilityZones")
+	}
+	return nil
+}
+
+// checkDedicatedHostCapacity checks that the flavor is supported by the dedicated host
+// and the host has enough free vCPUs and memory for it
+func checkDedicatedHostCapacity(host *hosts.Host, flavor *Flavor) error {
+	if capacities := host.HostProperties.InstanceCapacities; len(capacities) > 0 {
+		var supported []string
+		found := false
+		for _, capacity := range capacities {
+			supported = append(supported, capacity.Flavor)
+			found = found || capacity.Flavor == flavor.ID || capacity.Flavor == flavor.Name
+		}
+		if !found {
+			return fmt.Errorf("flavor `%s` is not supported by dedicated host `%s`, supported flavors: [%s]",
+				flavor.Name, host.ID, strings.Join(supported, ", "))
+		}
+	}
+	if host.AvailableVcpus < flavor.VCPUs || host.AvailableMemory < flavor.RAM {
+		return fmt.Errorf("dedicated host `%s` has not enough capacity for flavor `%s`: "+
+			"requested %d vCPUs and %dMB RAM, available %d vCPUs and %dMB RAM",
+			host.ID, flavor.Name, flavor.VCPUs, flavor.RAM, host.AvailableVcpus, host.AvailableMemory)
+	}
+	return nil
+}
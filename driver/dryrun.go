@@ -0,0 +1,88 @@
+package opentelekomcloud
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// errDryRun is returned by PreCreateCheck in dry-run mode to stop machine creation
+var errDryRun = errors.New("dry run finished successfully, no resources were created")
+
+// dryRunSummary describes resources which would be created or reused by the driver
+func (d *Driver) dryRunSummary() []string {
+	var summary []string
+	existing := func(kind, id string) {
+		summary = append(summary, fmt.Sprintf("existing %s `%s` will be used", kind, id))
+	}
+	created := func(kind, name string) {
+		summary = append(summary, fmt.Sprintf("%s `%s` will be created", kind, name))
+	}
+
+	if d.VpcID.Value != "" {
+		existing("VPC", d.VpcID.Value)
+	} else {
+		created("VPC", d.VpcName)
+	}
+	if d.SubnetID.Value != "" {
+		existing("subnet", d.SubnetID.Value)
+	} else {
+		created("subnet", fmt.Sprintf("%s (%s)", d.SubnetName, d.SubnetCIDR))
+	}
+	for _, subnetID := range d.AdditionalSubnetIDs {
+		existing("additional subnet", subnetID)
+	}
+	if d.UseNatGateway {
+		created("NAT gateway", d.MachineName+"-nat")
+	}
+	for _, sgID := range d.SecurityGroupIDs {
+		existing("security group", sgID)
+	}
+	if d.ManagedSecurityGroup != "" {
+		created("security group", d.ManagedSecurityGroup+"-<unique>")
+	}
+	if d.ServerGroupID.Value != "" {
+		existing("server group", d.ServerGroupID.Value)
+	} else if d.ServerGroup != "" {
+		created("server group", d.ServerGroup)
+	}
+	switch {
+	case d.KeyPairName.Value != "":
+		existing("key pair", d.KeyPairName.Value)
+	case d.passwordOnly():
+		summary = append(summary, "no key pair will be used, generated SSH key will be injected via user data")
+	default:
+		created("key pair", d.MachineName+"-<unique>")
+	}
+	switch {
+	case d.skipEIPCreation:
+		summary = append(summary, "no elastic IP will be used, machine is accessed by private address")
+	case d.ElasticIP.Value != "":
+		existing("elastic IP", d.ElasticIP.Value)
+	case d.SharedBandwidthID != "":
+		created("elastic IP", fmt.Sprintf("shared bandwidth `%s`", d.SharedBandwidthID))
+	default:
+		created("elastic IP", fmt.Sprintf("%d MBit/s", d.eipConfig.BandwidthSize))
+	}
+	created("instance", fmt.Sprintf("%s (flavor `%s`, image `%s`, availability zone `%s`)",
+		d.instanceName(), d.FlavorID, d.RootVolumeOpts.SourceID, d.AvailabilityZone))
+	if d.DedicatedHostID != "" {
+		summary = append(summary, fmt.Sprintf("instance will be created on dedicated host `%s`", d.DedicatedHostID))
+	}
+	if d.BootFromImage {
+		summary = append(summary, "instance will be booted from the image, root disk is removed with the instance")
+	}
+	if d.prePaid() {
+		summary = append(summary, fmt.Sprintf("instance will be prePaid for %d %s(s)", d.PeriodNum, d.PeriodType))
+	}
+	return summary
+}
+
+// dryRun resolves all existing resources and logs summary of the resources to be created
+func (d *Driver) dryRun() error {
+	if err := d.resolveIDs(); err != nil {
+		return err
+	}
+	d.logger().Infof("Dry run summary:\n - %s", strings.Join(d.dryRunSummary(), "\n - "))
+	return errDryRun
+}
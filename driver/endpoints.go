@@ -0,0 +1,51 @@
+package opentelekomcloud
+
+import (
+	"fmt"
+	"net/url"
+
+	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
+)
+
+// validateEndpoint checks that endpoint override is a full URL
+func validateEndpoint(name, endpoint string) error {
+	if endpoint == "" {
+		return nil
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid %s endpoint `%s`: %s", name, endpoint, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid %s endpoint `%s`: full URL is expected", name, endpoint)
+	}
+	return nil
+}
+
+// overrideEndpointLocator makes provider client return given endpoints for
+// service types instead of ones from service catalog
+func overrideEndpointLocator(pc *golangsdk.ProviderClient, overrides map[string]string) {
+	locator := pc.EndpointLocator
+	pc.EndpointLocator = func(opts golangsdk.EndpointOpts) (string, error) {
+		if endpoint := overrides[opts.Type]; endpoint != "" {
+			return golangsdk.NormalizeURL(endpoint), nil
+		}
+		return locator(opts)
+	}
+}
+
+// overrideEndpoints applies configured compute and network endpoints to the authenticated client
+func (d *Driver) overrideEndpoints() error {
+	if d.ComputeEndpoint == "" && d.NetworkEndpoint == "" {
+		return nil
+	}
+	pc, err := d.providerClient()
+	if err != nil {
+		return err
+	}
+	overrideEndpointLocator(pc, map[string]string{
+		"compute": d.ComputeEndpoint,
+		"network": d.NetworkEndpoint,
+	})
+	return nil
+}
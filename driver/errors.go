@@ -0,0 +1,56 @@
+package opentelekomcloud
+
+import (
+	"errors"
+	"fmt"
+
+	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
+)
+
+// Errors returned when resource doesn't exist, they can be checked with `errors.Is`
+var (
+	ErrInstanceNotFound        = errors.New("instance not found")
+	ErrVPCNotFound             = errors.New("VPC not found")
+	ErrSubnetNotFound          = errors.New("subnet not found")
+	ErrImageNotFound           = errors.New("image not found")
+	ErrVolumeNotFound          = errors.New("volume not found")
+	ErrNatGatewayNotFound      = errors.New("NAT gateway not found")
+	ErrSnatRuleNotFound        = errors.New("SNAT rule not found")
+	ErrPeeringNotFound         = errors.New("VPC peering not found")
+	ErrSharedBandwidthNotFound = errors.New("shared bandwidth not found")
+	ErrFlavorNotFound          = errors.New("flavor not found")
+	ErrDedicatedHostNotFound   = errors.New("dedicated host not found")
+)
+
+// ErrSubnetCIDRConflict is returned when subnet CIDR overlaps CIDR of existing subnet in the VPC
+var ErrSubnetCIDRConflict = errors.New("subnet CIDR conflict")
+
+// ErrFloatingIPInUse is returned when bound floating IP is deleted without force
+var ErrFloatingIPInUse = errors.New("floating IP is in use")
+
+// notFoundError wraps SDK 404 error, so it matches both the sentinel error
+// with `errors.Is` and golangsdk.ErrDefault404 with `errors.As`
+type notFoundError struct {
+	sentinel error
+	err      error
+}
+
+func (e *notFoundError) Error() string {
+	return fmt.Sprintf("%s: %s", e.sentinel, e.err)
+}
+
+func (e *notFoundError) Is(target error) bool {
+	return target == e.sentinel
+}
+
+func (e *notFoundError) Unwrap() error {
+	return e.err
+}
+
+// wrapNotFound wraps SDK 404 error with the sentinel error, other errors are returned as is
+func wrapNotFound(err error, sentinel error) error {
+	if _, ok := err.(golangsdk.ErrDefault404); ok {
+		return &notFoundError{sentinel: sentinel, err: err}
+	}
+	return err
+}
@@ -0,0 +1,165 @@
+package opentelekomcloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/servers"
+)
+
+// listPageLimit is a number of items requested in single page of the list
+const listPageLimit = 100
+
+type listItem struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	CIDR string `json:"cidr"` // subnets only
+}
+
+// listAll goes through all pages of marker-paginated list, items are taken from `key` of the response body
+func listAll(client *golangsdk.ServiceClient, listURL string, query url.Values, key string) ([]listItem, error) {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("limit", strconv.Itoa(listPageLimit))
+	var result []listItem
+	for {
+		var body map[string]json.RawMessage
+		if _, err := client.Get(listURL+"?"+query.Encode(), &body, nil); err != nil {
+			return nil, err
+		}
+		var items []listItem
+		if raw, ok := body[key]; ok {
+			if err := json.Unmarshal(raw, &items); err != nil {
+				return nil, fmt.Errorf("failed to parse `%s` list: %s", key, err)
+			}
+		}
+		result = append(result, items...)
+		if len(items) < listPageLimit {
+			return result, nil
+		}
+		query.Set("marker", items[len(items)-1].ID)
+	}
+}
+
+// findInstance returns ID of the instance with given name, empty string is returned if instance is not found.
+// Name filter is a regular expression, so it's anchored to return exact matches only
+func (d *Driver) findInstance(name string) (string, error) {
+	if err := d.initComputeV2(); err != nil {
+		return "", err
+	}
+	opts := servers.ListOpts{Name: "^" + regexp.QuoteMeta(name) + "$"}
+	pages, err := servers.List(d.compute, opts).AllPages()
+	if err != nil {
+		return "", err
+	}
+	serverList, err := servers.ExtractServers(pages)
+	if err != nil {
+		return "", err
+	}
+	for _, server := range serverList {
+		if server.Name == name {
+			return server.ID, nil
+		}
+	}
+	return "", nil
+}
+
+// findVPC returns ID of the VPC with given name, empty string is returned if VPC is not found.
+// VPC API has no name filter, so VPCs are filtered by name on the client side
+func (d *Driver) findVPC(name string) (string, error) {
+	vpcList, err := listAll(d.vpc, d.vpc.ServiceURL(d.vpc.ProjectID, "vpcs"), nil, "vpcs")
+	if err != nil {
+		return "", err
+	}
+	var found []string
+	for _, vpc := range vpcList {
+		if vpc.Name == name {
+			found = append(found, vpc.ID)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", nil
+	case 1:
+		return found[0], nil
+	default:
+		return "", fmt.Errorf("multiple VPC found by name %s. Please provide VPC ID instead", name)
+	}
+}
+
+// findSubnet returns ID of the subnet with given name in the VPC, empty string is returned if subnet is not found
+func (d *Driver) findSubnet(vpcID, name string) (string, error) {
+	query := url.Values{}
+	if vpcID != "" {
+		query.Set("vpc_id", vpcID)
+	}
+	subnetList, err := listAll(d.vpc, d.vpc.ServiceURL(d.vpc.ProjectID, "subnets"), query, "subnets")
+	if err != nil {
+		return "", err
+	}
+	var found []string
+	for _, subnet := range subnetList {
+		if subnet.Name == name {
+			found = append(found, subnet.ID)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", nil
+	case 1:
+		return found[0], nil
+	default:
+		return "", fmt.Errorf("multiple Subnets found by name %s in VPC %s. "+
+			"Please provide Subnet ID instead", name, vpcID)
+	}
+}
+
+// findSecurityGroups returns IDs of security groups with given names or IDs.
+// Groups are requested by name first, groups not found by name are requested by ID
+func (d *Driver) findSecurityGroups(groups []string) ([]string, error) {
+	if len(groups) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, len(groups))
+	for _, filter := range []string{"name", "id"} {
+		query := url.Values{}
+		for i, group := range groups {
+			if ids[i] == "" {
+				query.Add(filter, group)
+			}
+		}
+		if len(query) == 0 {
+			break
+		}
+		groupList, err := listAll(d.network, d.network.ServiceURL("security-groups"), query, "security_groups")
+		if err != nil {
+			return nil, err
+		}
+		for i, group := range groups {
+			for _, found := range groupList {
+				if group == found.ID || group == found.Name {
+					ids[i] = found.ID
+					break
+				}
+			}
+		}
+	}
+	var found, missing []string
+	for i, id := range ids {
+		if id == "" {
+			missing = append(missing, groups[i])
+			continue
+		}
+		found = append(found, id)
+	}
+	if len(missing) > 0 {
+		return found, fmt.Errorf("some security groups failed to be found: %s", strings.Join(missing, ", "))
+	}
+	return found, nil
+}
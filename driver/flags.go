@@ -26,6 +26,10 @@ func (d *Driver) GetCreateFlags() []mcnflag.Flag {
 			EnvVar: "OS_CACERT",
 			Usage:  "CA certificate bundle to verify against",
 		},
+		mcnflag.BoolFlag{
+			Name:  "otc-insecure",
+			Usage: "Disable TLS certificate verification of API endpoints",
+		},
 		mcnflag.StringFlag{
 			Name:   "otc-domain-id",
 			EnvVar: "OS_DOMAIN_ID",
@@ -56,10 +60,30 @@ func (d *Driver) GetCreateFlags() []mcnflag.Flag {
 			EnvVar: "OS_PROJECT_ID",
 			Usage:  "OpenTelekomCloud project ID",
 		},
+		mcnflag.StringFlag{
+			Name:   "otc-iam-endpoint",
+			EnvVar: "OS_IAM_ENDPOINT",
+			Usage:  "IAM endpoint URL overriding authentication URL",
+		},
+		mcnflag.StringFlag{
+			Name:   "otc-compute-endpoint",
+			EnvVar: "OS_COMPUTE_ENDPOINT",
+			Usage:  "Compute endpoint URL overriding one from the service catalog",
+		},
+		mcnflag.StringFlag{
+			Name:   "otc-network-endpoint",
+			EnvVar: "OS_NETWORK_ENDPOINT",
+			Usage:  "Network endpoint URL overriding one from the service catalog",
+		},
+		mcnflag.StringFlag{
+			Name:   "otc-api-proxy",
+			EnvVar: "OS_API_PROXY",
+			Usage:  "Proxy URL used for API calls, overrides HTTP_PROXY and HTTPS_PROXY (except initial authentication request)",
+		},
 		mcnflag.StringFlag{
 			Name:   "otc-region",
 			EnvVar: "OS_REGION",
-			Usage:  "OpenTelekomCloud region name",
+			Usage:  "OpenTelekomCloud region name, one of `eu-de`, `eu-nl`, `eu-ch2`",
 			Value:  defaultRegion,
 		},
 		mcnflag.StringFlag{
@@ -75,8 +99,12 @@ func (d *Driver) GetCreateFlags() []mcnflag.Flag {
 		mcnflag.StringFlag{
 			Name:   "otc-availability-zone",
 			EnvVar: "OS_AVAILABILITY_ZONE",
-			Usage:  "OpenTelekomCloud availability zone",
-			Value:  defaultAZ,
+			Usage:  "OpenTelekomCloud availability zone, selected automatically if not set",
+		},
+		mcnflag.StringFlag{
+			Name:   "otc-availability-zones",
+			EnvVar: "OS_AVAILABILITY_ZONES",
+			Usage:  "Comma-separated list of availability zones, zone is selected by the machine name hash",
 		},
 		mcnflag.StringFlag{
 			Name:   "otc-flavor-id",
@@ -92,7 +120,7 @@ func (d *Driver) GetCreateFlags() []mcnflag.Flag {
 		mcnflag.StringFlag{
 			Name:   "otc-image-id",
 			EnvVar: "OS_IMAGE_ID",
-			Usage:  "OpenTelekomCloud image id to use for the instance",
+			Usage:  "OpenTelekomCloud image id to use for the instance, takes precedence over image name",
 		},
 		mcnflag.StringFlag{
 			Name:   "otc-image-name",
@@ -100,6 +128,15 @@ func (d *Driver) GetCreateFlags() []mcnflag.Flag {
 			Usage:  "OpenTelekomCloud image name to use for the instance",
 			Value:  defaultImage,
 		},
+		mcnflag.BoolFlag{
+			Name:  "otc-cloud-generated-key",
+			Usage: "Let OpenTelekomCloud generate key pair of the machine instead of generating SSH key locally",
+		},
+		mcnflag.StringFlag{
+			Name:   "otc-admin-password",
+			EnvVar: "OS_ADMIN_PASSWORD",
+			Usage:  "Password of the instance administrator, if no key pair is configured, key pair is not created and generated SSH key is injected via user data",
+		},
 		mcnflag.StringFlag{
 			Name:   "otc-keypair-name",
 			EnvVar: "OS_KEYPAIR_NAME",
@@ -127,6 +164,22 @@ func (d *Driver) GetCreateFlags() []mcnflag.Flag {
 			Usage:  "OpenTelekomCloud subnet name the machine will be connected on",
 			Value:  defaultSubnetName,
 		},
+		mcnflag.StringFlag{
+			Name:   "otc-subnet-cidr",
+			EnvVar: "OS_SUBNET_CIDR",
+			Usage:  "CIDR of the subnet created for the machine",
+			Value:  defaultSubnetCIDR,
+		},
+		mcnflag.StringFlag{
+			Name:   "otc-subnet-gateway",
+			EnvVar: "OS_SUBNET_GATEWAY",
+			Usage:  "Gateway IP of the subnet created for the machine (first address of the CIDR by default)",
+		},
+		mcnflag.StringFlag{
+			Name:   "otc-subnet-dns-servers",
+			EnvVar: "OS_SUBNET_DNS_SERVERS",
+			Usage:  "Comma-separated list of DNS servers of the subnet created for the machine",
+		},
 		mcnflag.StringFlag{
 			Name:   "otc-private-key-file",
 			EnvVar: "OS_PRIVATE_KEY_FILE",
@@ -146,10 +199,24 @@ func (d *Driver) GetCreateFlags() []mcnflag.Flag {
 			EnvVar: "OS_TOKEN",
 			Usage:  "OpenTelekomCloud authorization token",
 		},
+		mcnflag.StringFlag{
+			Name:   "otc-agency-name",
+			EnvVar: "OS_AGENCY_NAME",
+			Usage:  "Name of the agency to assume, `--otc-project-name` is used as delegated project",
+		},
+		mcnflag.StringFlag{
+			Name:   "otc-agency-domain",
+			EnvVar: "OS_AGENCY_DOMAIN",
+			Usage:  "Name of the domain which created the agency",
+		},
+		mcnflag.BoolFlag{
+			Name:  "otc-token-cache",
+			Usage: "Cache obtained token in the user cache directory and reuse it while it's valid (not used with AK/SK auth)",
+		},
 		mcnflag.StringFlag{
 			Name:   "otc-sec-groups",
 			EnvVar: "OS_SECURITY_GROUP",
-			Usage:  "Existing security groups to use, separated by comma",
+			Usage:  "Existing security groups (names or IDs) to use, separated by comma",
 		},
 		mcnflag.StringFlag{
 			Name:   "otc-eip",
@@ -165,30 +232,166 @@ func (d *Driver) GetCreateFlags() []mcnflag.Flag {
 		mcnflag.IntFlag{
 			Name:   "otc-bandwidth-size",
 			EnvVar: "OS_BANDWIDTH_SIZE",
-			Usage:  "OpenTelekomCloud bandwidth size",
+			Usage:  "OpenTelekomCloud bandwidth size in MBit/s (1-2000)",
 			Value:  100,
 		},
 		mcnflag.StringFlag{
 			Name:   "otc-bandwidth-type",
 			EnvVar: "OS_BANDWIDTH_TYPE",
-			Usage:  "OpenTelekomCloud bandwidth share type",
+			Usage:  "OpenTelekomCloud bandwidth share type (PER or WHOLE)",
 			Value:  "PER",
 		},
+		mcnflag.BoolFlag{
+			Name:  "otc-dry-run",
+			Usage: "Validate configuration and print summary of resources to be created without creating anything",
+		},
+		mcnflag.StringFlag{
+			Name:   "otc-routes",
+			EnvVar: "OS_ROUTES",
+			Usage:  "Comma-separated list of `cidr=nexthop` static routes added to the machine VPC",
+		},
+		mcnflag.StringFlag{
+			Name:   "otc-peer-vpc-id",
+			EnvVar: "OS_PEER_VPC_ID",
+			Usage:  "ID of VPC to be peered with the machine VPC",
+		},
+		mcnflag.StringFlag{
+			Name:   "otc-peer-project-id",
+			EnvVar: "OS_PEER_PROJECT_ID",
+			Usage:  "ID of the project owning peer VPC, required if the VPC belongs to another project",
+		},
+		mcnflag.StringFlag{
+			Name:   "otc-peer-routes",
+			EnvVar: "OS_PEER_ROUTES",
+			Usage:  "Comma-separated list of CIDRs routed via the VPC peering",
+		},
+		mcnflag.BoolFlag{
+			Name:  "otc-api-debug",
+			Usage: "Write API requests and responses to the debug log, credentials are redacted",
+		},
+		mcnflag.StringFlag{
+			Name:   "otc-attach-volume-id",
+			EnvVar: "OS_ATTACH_VOLUME_ID",
+			Usage:  "ID of existing volume attached to the instance, the volume is detached but not deleted on machine removal",
+		},
+		mcnflag.StringFlag{
+			Name:   "otc-boot-from-volume",
+			EnvVar: "OS_BOOT_FROM_VOLUME",
+			Usage:  "Boot the instance from persistent root volume created from the image (`true`) or from the image directly (`false`)",
+			Value:  "true",
+		},
+		mcnflag.StringFlag{
+			Name:   "otc-delete-volumes-on-remove",
+			EnvVar: "OS_DELETE_VOLUMES_ON_REMOVE",
+			Usage:  "Delete root and data volumes created by the driver on machine removal (`true` or `false`)",
+			Value:  "true",
+		},
+		mcnflag.StringFlag{
+			Name:   "otc-shared-bandwidth-id",
+			EnvVar: "OS_SHARED_BANDWIDTH_ID",
+			Usage:  "ID of existing shared bandwidth joined by created elastic IP instead of dedicated bandwidth",
+		},
+		mcnflag.BoolFlag{
+			Name:  "otc-create-nat-gateway",
+			Usage: "Create NAT gateway with SNAT rule for the machine subnet, so it can reach the internet without elastic IP",
+		},
+		mcnflag.BoolFlag{
+			Name:  "otc-skip-lookup-cache",
+			Usage: "Don't reuse results of flavor and image lookups made by other machines created in the same process",
+		},
+		mcnflag.BoolFlag{
+			Name:  "otc-skip-flavor-az-check",
+			Usage: "Don't check that the flavor is available in the configured availability zones",
+		},
+		mcnflag.BoolFlag{
+			Name:  "otc-skip-quota-check",
+			Usage: "Don't check project quotas before creating resources",
+		},
+		mcnflag.StringFlag{
+			Name:   "otc-billing-mode",
+			EnvVar: "OS_BILLING_MODE",
+			Usage:  "Billing mode of the instance (postPaid or prePaid)",
+			Value:  billingModePostPaid,
+		},
+		mcnflag.StringFlag{
+			Name:   "otc-period-type",
+			EnvVar: "OS_PERIOD_TYPE",
+			Usage:  "Subscription period type of prePaid instance (month or year)",
+			Value:  periodTypeMonth,
+		},
+		mcnflag.IntFlag{
+			Name:   "otc-period-num",
+			EnvVar: "OS_PERIOD_NUM",
+			Usage:  "Number of subscription periods of prePaid instance",
+			Value:  1,
+		},
+		mcnflag.BoolFlag{
+			Name:  "otc-auto-recovery",
+			Usage: "Enable auto-recovery of the instance on host failure",
+		},
+		mcnflag.BoolFlag{
+			Name:  "otc-force-restart",
+			Usage: "Use hard reboot when restarting the machine",
+		},
+		mcnflag.BoolFlag{
+			Name:  "otc-stop-before-image",
+			Usage: "Stop the instance while creating an image from it",
+		},
+		mcnflag.BoolFlag{
+			Name:  "otc-reuse-existing",
+			Usage: "Reuse existing instance with the machine name instead of failing",
+		},
+		mcnflag.BoolFlag{
+			Name:  "otc-bind-eip-on-create",
+			Usage: "Bind elastic IP to the instance on creation instead of binding it after the instance is created",
+		},
 		mcnflag.BoolFlag{
 			Name:  "otc-skip-eip",
 			Usage: "If set, elastic IP won't be created",
 		},
+		mcnflag.StringFlag{
+			Name:   "otc-bastion-host",
+			EnvVar: "OS_BASTION_HOST",
+			Usage:  "Bastion host used to reach machine private address via SSH",
+		},
+		mcnflag.StringFlag{
+			Name:   "otc-bastion-user",
+			EnvVar: "OS_BASTION_USER",
+			Usage:  "Bastion host SSH user (machine SSH user by default)",
+		},
+		mcnflag.IntFlag{
+			Name:   "otc-bastion-port",
+			EnvVar: "OS_BASTION_PORT",
+			Usage:  "Bastion host SSH port",
+			Value:  defaultBastionPort,
+		},
+		mcnflag.StringFlag{
+			Name:   "otc-bastion-key",
+			EnvVar: "OS_BASTION_KEY",
+			Usage:  "Private key file used for bastion host (machine key by default)",
+		},
+		mcnflag.BoolFlag{
+			Name:  "otc-skip-floating-ip",
+			Usage: "If set, no floating IP is allocated or bound, machine is accessed by its private address",
+		},
 		mcnflag.IntFlag{
 			Name:   "otc-ip-version",
 			EnvVar: "OS_IP_VERSION",
 			Usage:  "OpenTelekomCloud version of IP address assigned for the machine",
 			Value:  4,
 		},
+		mcnflag.BoolFlag{
+			Name:  "otc-ipv6",
+			Usage: "If set, IPv6 will be enabled for created subnet and the machine",
+		},
+		mcnflag.BoolFlag{
+			Name:  "otc-use-ipv6-ssh",
+			Usage: "If set, machine IPv6 address will be used for SSH connection",
+		},
 		mcnflag.StringFlag{
 			Name:   "otc-ssh-user",
 			EnvVar: "OS_SSH_USER",
-			Usage:  "Machine SSH username",
-			Value:  defaultSSHUser,
+			Usage:  "Machine SSH username, detected from the image if not set",
 		},
 		mcnflag.IntFlag{
 			Name:   "otc-ssh-port",
@@ -206,10 +409,37 @@ func (d *Driver) GetCreateFlags() []mcnflag.Flag {
 			Name:  "otc-skip-default-sg",
 			Usage: "Don't create default security group",
 		},
+		mcnflag.StringFlag{
+			Name:   "otc-sec-group-egress",
+			EnvVar: "OS_SECURITY_GROUP_EGRESS",
+			Usage:  "Comma-separated list of `from[-to]:cidr` egress TCP rules of created security group, all egress traffic is allowed if not set",
+		},
+		mcnflag.StringFlag{
+			Name:   "otc-sec-group-source-cidr",
+			EnvVar: "OS_SECURITY_GROUP_SOURCE_CIDR",
+			Usage:  "Comma-separated list of CIDRs allowed to access SSH and docker ports of created security group",
+		},
+		mcnflag.StringFlag{
+			Name:   "otc-open-ports",
+			EnvVar: "OS_OPEN_PORTS",
+			Usage:  "Comma-separated list of `[protocol:]from[-to]` additional ports to open in created security group, e.g. `udp:5000-6000`",
+		},
+		mcnflag.BoolFlag{
+			Name:  "otc-allow-ping",
+			Usage: "Allow ICMP ingress traffic in created security group",
+		},
+		mcnflag.BoolFlag{
+			Name:  "otc-disable-port-security",
+			Usage: "Disable port security (source/destination check) of the primary instance NIC, e.g. for NAT or VPN gateways",
+		},
+		mcnflag.BoolFlag{
+			Name:  "otc-sec-group-create",
+			Usage: "Create default security group even if existing security groups are used",
+		},
 		mcnflag.StringFlag{
 			Name:   "otc-server-group",
 			EnvVar: "OS_SERVER_GROUP",
-			Usage:  "Define server group where server will be created",
+			Usage:  "Define server group where server will be created, anti-affinity group is created if it doesn't exist",
 		},
 		mcnflag.StringFlag{
 			Name:   "otc-server-group-id",
@@ -222,16 +452,81 @@ func (d *Driver) GetCreateFlags() []mcnflag.Flag {
 			Usage:  "Set volume size of root partition",
 			Value:  defaultVolumeSize,
 		},
+		mcnflag.StringFlag{
+			Name:   "otc-dedicated-host-id",
+			EnvVar: "OS_DEDICATED_HOST_ID",
+			Usage:  "ID of the dedicated host (DeH) where the instance is created",
+		},
+		mcnflag.IntFlag{
+			Name:   "otc-root-volume-iops",
+			EnvVar: "OS_ROOT_VOLUME_IOPS",
+			Usage:  "Provisioned IOPS of the root volume, can be used only with ESSD volume type",
+		},
 		mcnflag.StringFlag{
 			Name:   "otc-root-volume-type",
 			EnvVar: "OS_ROOT_VOLUME_TYPE",
-			Usage:  "Set volume type of root partition (one of SATA, SAS, SSD)",
+			Usage:  "Set volume type of root partition (one of SATA, SAS, SSD, ESSD)",
 			Value:  defaultVolumeType,
 		},
+		mcnflag.StringFlag{
+			Name:   "otc-volume-kms-key-id",
+			EnvVar: "OS_VOLUME_KMS_KEY_ID",
+			Usage:  "ID of KMS key used for encryption of root and data volumes",
+		},
+		mcnflag.StringFlag{
+			Name:   "otc-data-disks",
+			EnvVar: "OS_DATA_DISKS",
+			Usage:  "Comma-separated list of data disks to attach in `type:size` format",
+		},
+		mcnflag.IntFlag{
+			Name:   "otc-wait-timeout",
+			EnvVar: "OS_WAIT_TIMEOUT",
+			Usage:  "Timeout of waiting for resource status (in seconds)",
+			Value:  defaultWaitTimeout,
+		},
+		mcnflag.IntFlag{
+			Name:   "otc-wait-interval",
+			EnvVar: "OS_WAIT_INTERVAL",
+			Usage:  "Interval of polling resource status while waiting for it (in seconds)",
+			Value:  defaultWaitInterval,
+		},
+		mcnflag.IntFlag{
+			Name:   "otc-ssh-grace-period",
+			EnvVar: "OS_SSH_GRACE_PERIOD",
+			Usage: "Time to wait for SSH port after elastic IP binding before checking instance security groups " +
+				"to tell security group propagation from slow boot (in seconds), 0 disables the check",
+		},
+		mcnflag.IntFlag{
+			Name:   "otc-api-retries",
+			EnvVar: "OS_API_RETRIES",
+			Usage:  "Number of retries of API calls failed with transient errors",
+			Value:  defaultAPIRetries,
+		},
+		mcnflag.IntFlag{
+			Name:   "otc-api-retry-delay",
+			EnvVar: "OS_API_RETRY_DELAY",
+			Usage:  "Base delay between retries of API calls, doubled with every retry (in seconds)",
+			Value:  defaultAPIRetryDelay,
+		},
+		mcnflag.StringFlag{
+			Name:   "otc-private-ip",
+			EnvVar: "OS_PRIVATE_IP",
+			Usage:  "Fixed private IP of the primary machine NIC",
+		},
+		mcnflag.StringFlag{
+			Name:   "otc-additional-subnets",
+			EnvVar: "OS_ADDITIONAL_SUBNETS",
+			Usage:  "Comma-separated list of subnet IDs where additional machine NICs are created",
+		},
 		mcnflag.StringFlag{
 			Name:   "otc-tags",
 			EnvVar: "OS_TAGS",
-			Usage:  "Comma-separated list of instance tags",
+			Usage:  "Comma-separated list of `key=value` (or plain) tags of created instance, VPC, subnet and elastic IP",
+		},
+		mcnflag.StringFlag{
+			Name:   "otc-metadata",
+			EnvVar: "OS_METADATA",
+			Usage:  "Comma-separated list of `key=value` instance metadata items",
 		},
 	}
 }
@@ -241,6 +536,7 @@ func (d *Driver) SetConfigFromFlags(flags drivers.DriverOptions) error {
 	d.AuthURL = flags.String("otc-auth-url")
 	d.Cloud = flags.String("otc-cloud")
 	d.CACert = flags.String("otc-cacert")
+	d.Insecure = flags.Bool("otc-insecure")
 	d.DomainID = flags.String("otc-domain-id")
 	d.DomainName = flags.String("otc-domain-name")
 	d.Username = flags.String("otc-username")
@@ -249,6 +545,25 @@ func (d *Driver) SetConfigFromFlags(flags drivers.DriverOptions) error {
 	d.ProjectID = flags.String("otc-project-id")
 	d.Region = flags.String("otc-region")
 	d.AvailabilityZone = flags.String("otc-availability-zone")
+	if zones := flags.String("otc-availability-zones"); zones != "" {
+		d.AvailabilityZones = strings.Split(zones, ",")
+	}
+	d.setRegionDefaults()
+	if iamEndpoint := flags.String("otc-iam-endpoint"); iamEndpoint != "" {
+		if err := validateEndpoint("IAM", iamEndpoint); err != nil {
+			return err
+		}
+		d.AuthURL = iamEndpoint
+	}
+	d.ComputeEndpoint = flags.String("otc-compute-endpoint")
+	d.APIProxy = flags.String("otc-api-proxy")
+	d.WaitTimeout = flags.Int("otc-wait-timeout")
+	d.WaitInterval = flags.Int("otc-wait-interval")
+	d.SSHGracePeriod = flags.Int("otc-ssh-grace-period")
+	d.APIRetries = flags.Int("otc-api-retries")
+	d.APIRetryDelay = flags.Int("otc-api-retry-delay")
+	d.APIDebug = flags.Bool("otc-api-debug")
+	d.NetworkEndpoint = flags.String("otc-network-endpoint")
 	d.EndpointType = flags.String("otc-endpoint-type")
 	d.FlavorID = flags.String("otc-flavor-id")
 	d.FlavorName = flags.String("otc-flavor-name")
@@ -257,21 +572,39 @@ func (d *Driver) SetConfigFromFlags(flags drivers.DriverOptions) error {
 	d.VpcName = flags.String("otc-vpc-name")
 	d.SubnetID = managedSting{Value: flags.String("otc-subnet-id")}
 	d.SubnetName = flags.String("otc-subnet-name")
+	d.SubnetCIDR = flags.String("otc-subnet-cidr")
+	d.SubnetGateway = flags.String("otc-subnet-gateway")
+	if dns := flags.String("otc-subnet-dns-servers"); dns != "" {
+		d.SubnetDNSServers = strings.Split(dns, ",")
+	}
 	d.ElasticIP = managedSting{Value: flags.String("otc-eip")}
 	d.IPVersion = flags.Int("otc-ip-version")
+	d.IPv6 = flags.Bool("otc-ipv6")
+	d.UseIPv6SSH = flags.Bool("otc-use-ipv6-ssh")
 	d.SSHUser = flags.String("otc-ssh-user")
 	d.SSHPort = flags.Int("otc-ssh-port")
 	d.KeyPairName = managedSting{Value: flags.String("otc-keypair-name")}
 	d.PrivateKeyFile = flags.String("otc-private-key-file")
+	d.CloudGeneratedKey = flags.Bool("otc-cloud-generated-key")
+	d.AdminPassword = flags.String("otc-admin-password")
 	d.Token = flags.String("otc-token")
+	d.UseTokenCache = flags.Bool("otc-token-cache")
+	d.AgencyName = flags.String("otc-agency-name")
+	d.AgencyDomain = flags.String("otc-agency-domain")
 	d.UserDataFile = flags.String("otc-user-data-file")
 	d.UserData = []byte(flags.String("otc-user-data-raw"))
 	d.ServerGroup = flags.String("otc-server-group")
-	d.ServerGroupID = flags.String("otc-server-group-id")
-	tags := flags.String("otc-tags")
-	if tags != "" {
-		d.Tags = strings.Split(tags, ",")
+	d.ServerGroupID = managedSting{Value: flags.String("otc-server-group-id")}
+	tags, err := parseTags(flags.String("otc-tags"))
+	if err != nil {
+		return err
+	}
+	d.Tags = tags
+	metadata, err := parseMetadata(flags.String("otc-metadata"))
+	if err != nil {
+		return err
 	}
+	d.Metadata = metadata
 	d.AccessKey = flags.String("otc-access-key")
 	d.SecretKey = flags.String("otc-secret-key")
 
@@ -281,18 +614,108 @@ func (d *Driver) SetConfigFromFlags(flags drivers.DriverOptions) error {
 		Type:     flags.String("otc-root-volume-type"),
 	}
 
+	d.RootVolumeIOPS = flags.Int("otc-root-volume-iops")
+	d.DedicatedHostID = flags.String("otc-dedicated-host-id")
+	d.KMSKeyID = flags.String("otc-volume-kms-key-id")
+	if disks := flags.String("otc-data-disks"); disks != "" {
+		dataVolumes, err := parseDataDisks(disks)
+		if err != nil {
+			return err
+		}
+		d.DataVolumes = dataVolumes
+	}
+
 	d.eipConfig = &services.ElasticIPOpts{
 		IPType:        flags.String("otc-eip-type"),
 		BandwidthSize: flags.Int("otc-bandwidth-size"),
 		BandwidthType: flags.String("otc-bandwidth-type"),
 	}
-	d.skipEIPCreation = flags.Bool("otc-skip-eip")
+	d.skipEIPCreation = flags.Bool("otc-skip-eip") || flags.Bool("otc-skip-floating-ip")
+	d.SharedBandwidthID = flags.String("otc-shared-bandwidth-id")
+	d.BindEIPOnCreate = flags.Bool("otc-bind-eip-on-create")
+	d.AttachVolumeID = flags.String("otc-attach-volume-id")
+	if deleteVolumes := flags.String("otc-delete-volumes-on-remove"); deleteVolumes != "" {
+		value, err := parseBoolOption("otc-delete-volumes-on-remove", deleteVolumes)
+		if err != nil {
+			return err
+		}
+		d.KeepVolumesOnRemove = !value
+	}
+	if bootFromVolume := flags.String("otc-boot-from-volume"); bootFromVolume != "" {
+		value, err := parseBoolOption("otc-boot-from-volume", bootFromVolume)
+		if err != nil {
+			return err
+		}
+		d.BootFromImage = !value
+	}
+	d.ReuseExisting = flags.Bool("otc-reuse-existing")
+	d.StopBeforeImage = flags.Bool("otc-stop-before-image")
+	d.ForceRestart = flags.Bool("otc-force-restart")
+	d.AutoRecovery = flags.Bool("otc-auto-recovery")
+	d.BillingMode = flags.String("otc-billing-mode")
+	d.PeriodType = flags.String("otc-period-type")
+	d.PeriodNum = flags.Int("otc-period-num")
+	d.DryRun = flags.Bool("otc-dry-run")
+	d.SkipQuotaCheck = flags.Bool("otc-skip-quota-check")
+	d.SkipFlavorZoneCheck = flags.Bool("otc-skip-flavor-az-check")
+	d.SkipLookupCache = flags.Bool("otc-skip-lookup-cache")
+	d.UseNatGateway = flags.Bool("otc-create-nat-gateway")
+	d.BastionHost = flags.String("otc-bastion-host")
+	d.BastionUser = flags.String("otc-bastion-user")
+	d.BastionPort = flags.Int("otc-bastion-port")
+	d.BastionKeyFile = flags.String("otc-bastion-key")
+
+	d.PrivateIP = flags.String("otc-private-ip")
+	if subnets := flags.String("otc-additional-subnets"); subnets != "" {
+		d.AdditionalSubnetIDs = strings.Split(subnets, ",")
+	}
 
 	if sg := flags.String("otc-sec-groups"); sg != "" {
 		d.SecurityGroups = strings.Split(sg, ",")
 	}
 
-	if !flags.Bool("otc-skip-default-sg") {
+	if egress := flags.String("otc-sec-group-egress"); egress != "" {
+		egressRules, err := parseEgressRules(egress)
+		if err != nil {
+			return err
+		}
+		d.EgressRules = egressRules
+	}
+
+	if routes := flags.String("otc-routes"); routes != "" {
+		vpcRoutes, err := parseRoutes(routes)
+		if err != nil {
+			return err
+		}
+		d.Routes = vpcRoutes
+	}
+
+	d.PeerVpcID = flags.String("otc-peer-vpc-id")
+	d.PeerProjectID = flags.String("otc-peer-project-id")
+	if cidrs := flags.String("otc-peer-routes"); cidrs != "" {
+		peerRoutes, err := parsePeerRoutes(cidrs)
+		if err != nil {
+			return err
+		}
+		d.PeerRoutes = peerRoutes
+	}
+
+	if cidrs := flags.String("otc-sec-group-source-cidr"); cidrs != "" {
+		d.SourceCIDRs = strings.Split(cidrs, ",")
+	}
+
+	if ports := flags.String("otc-open-ports"); ports != "" {
+		openPorts, err := parseOpenPorts(ports)
+		if err != nil {
+			return err
+		}
+		d.OpenPorts = openPorts
+	}
+
+	d.AllowPing = flags.Bool("otc-allow-ping")
+	d.DisablePortSecurity = flags.Bool("otc-disable-port-security")
+
+	if !flags.Bool("otc-skip-default-sg") && (len(d.SecurityGroups) == 0 || flags.Bool("otc-sec-group-create")) {
 		d.ManagedSecurityGroup = defaultSecurityGroup
 	}
 
@@ -0,0 +1,131 @@
+package opentelekomcloud
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Flavor contains ECS flavor details
+type Flavor struct {
+	ID    string
+	Name  string
+	VCPUs int
+	// RAM in MB
+	RAM int
+	// Disk in GB
+	Disk       int
+	ExtraSpecs map[string]string
+	// GPU is set for GPU-accelerated flavors
+	GPU bool
+	// Dedicated is set for flavors with dedicated (not shared) vCPUs
+	Dedicated bool
+}
+
+type ecsFlavor struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	VCPUs      interface{}       `json:"vcpus"`
+	RAM        interface{}       `json:"ram"`
+	Disk       interface{}       `json:"disk"`
+	ExtraSpecs map[string]string `json:"os_extra_specs"`
+}
+
+const (
+	flavorConditionComputeSpec = "cond:compute"
+	flavorAutoRecovery         = "autorecovery"
+	flavorPerformanceType      = "ecs:performancetype"
+	flavorCPUPolicy            = "hw:cpu_policy"
+
+	performanceTypeGPU = "gpu"
+	cpuPolicyDedicated = "dedicated"
+
+	// minDockerRAM is RAM size (in MB) recommended for running docker engine
+	minDockerRAM = 1024
+)
+
+// supportsAutoRecovery reports if flavor supports ECS auto-recovery
+func (f Flavor) supportsAutoRecovery() bool {
+	return strings.Contains(f.ExtraSpecs[flavorConditionComputeSpec], flavorAutoRecovery)
+}
+
+// toInt converts number which can be returned either as a number or as a string
+func toInt(value interface{}) int {
+	switch v := value.(type) {
+	case float64:
+		return int(v)
+	case string:
+		i, _ := strconv.Atoi(v)
+		return i
+	default:
+		return 0
+	}
+}
+
+// ListFlavors returns flavors available in given availability zone (or in all zones if empty)
+// sorted by vCPU count and RAM size
+func (d *Driver) ListFlavors(az string) ([]Flavor, error) {
+	if err := d.initComputeV1(); err != nil {
+		return nil, err
+	}
+	listURL := d.ecs.ServiceURL("cloudservers", "flavors")
+	if az != "" {
+		listURL += "?" + url.Values{"availability_zone": {az}}.Encode()
+	}
+	var body struct {
+		Flavors []ecsFlavor `json:"flavors"`
+	}
+	if _, err := d.ecs.Get(listURL, &body, nil); err != nil {
+		return nil, fmt.Errorf("failed to list flavors: %s", logHttp500(err))
+	}
+	result := make([]Flavor, len(body.Flavors))
+	for i, flavor := range body.Flavors {
+		result[i] = Flavor{
+			ID:         flavor.ID,
+			Name:       flavor.Name,
+			VCPUs:      toInt(flavor.VCPUs),
+			RAM:        toInt(flavor.RAM),
+			Disk:       toInt(flavor.Disk),
+			ExtraSpecs: flavor.ExtraSpecs,
+			GPU:        flavor.ExtraSpecs[flavorPerformanceType] == performanceTypeGPU,
+			Dedicated:  flavor.ExtraSpecs[flavorCPUPolicy] == cpuPolicyDedicated,
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].VCPUs != result[j].VCPUs {
+			return result[i].VCPUs < result[j].VCPUs
+		}
+		return result[i].RAM < result[j].RAM
+	})
+	return result, nil
+}
+
+// FindFlavorDetails returns details of the flavor with given name or ID,
+// ErrFlavorNotFound is returned if there is no such flavor
+func (d *Driver) FindFlavorDetails(name string) (*Flavor, error) {
+	flavors, err := d.ListFlavors("")
+	if err != nil {
+		return nil, err
+	}
+	for _, flavor := range flavors {
+		if flavor.ID == name || flavor.Name == name {
+			return &flavor, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: `%s`", ErrFlavorNotFound, name)
+}
+
+// checkFlavorRAM warns if the instance flavor has not enough RAM for the docker engine
+func (d *Driver) checkFlavorRAM() error {
+	flavor, err := d.findFlavorDetails()
+	if err != nil {
+		return err
+	}
+	if flavor.RAM < minDockerRAM {
+		d.logger().Warnf("Flavor `%s` has %d MB of RAM, at least %d MB is recommended for docker engine",
+			flavor.Name, flavor.RAM, minDockerRAM)
+	}
+	return nil
+}
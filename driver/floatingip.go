@@ -0,0 +1,164 @@
+package opentelekomcloud
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/hashicorp/go-multierror"
+	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v1/eips"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/ports"
+	"github.com/opentelekomcloud/gophertelekomcloud/pagination"
+)
+
+// FloatingIP contains details of project elastic IP
+type FloatingIP struct {
+	ID      string
+	Address string
+	Status  string
+	// PortID and InstanceID are empty if the IP is not bound
+	PortID     string
+	InstanceID string
+}
+
+// ListFloatingIPs returns all elastic IPs of the project together with instances they are bound to
+func (d *Driver) ListFloatingIPs() ([]FloatingIP, error) {
+	if err := d.initNetwork(); err != nil {
+		return nil, err
+	}
+	var result []FloatingIP
+	err := eips.List(d.vpc, nil).EachPage(func(page pagination.Page) (bool, error) {
+		eipList, err := eips.ExtractEips(page)
+		if err != nil {
+			return false, err
+		}
+		for _, eip := range eipList {
+			result = append(result, FloatingIP{
+				ID:      eip.ID,
+				Address: eip.PublicAddress,
+				Status:  eip.Status,
+				PortID:  eip.PortID,
+			})
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list elastic IPs: %s", logHttp500(err))
+	}
+	devices, err := d.portDevices(result)
+	if err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].InstanceID = devices[result[i].PortID]
+	}
+	return result, nil
+}
+
+// portDevices returns IDs of devices (instances) owning ports of bound floating IPs by port ID.
+// Only ports of bound IPs are requested, port IDs are filtered in batches to keep request URLs short
+func (d *Driver) portDevices(ips []FloatingIP) (map[string]string, error) {
+	devices := make(map[string]string)
+	var portIDs []string
+	for _, ip := range ips {
+		if ip.PortID != "" {
+			portIDs = append(portIDs, ip.PortID)
+		}
+	}
+	for start := 0; start < len(portIDs); start += listPageLimit {
+		end := start + listPageLimit
+		if end > len(portIDs) {
+			end = len(portIDs)
+		}
+		query := url.Values{"fields": {"id", "device_id"}, "id": portIDs[start:end]}
+		var body struct {
+			Ports []ports.Port `json:"ports"`
+		}
+		if _, err := d.network.Get(d.network.ServiceURL("ports")+"?"+query.Encode(), &body, nil); err != nil {
+			return nil, fmt.Errorf("failed to list ports: %s", logHttp500(err))
+		}
+		for _, port := range body.Ports {
+			devices[port.ID] = port.DeviceID
+		}
+	}
+	return devices, nil
+}
+
+// bindFloatingIP binds floating IP to the instance and waits for the IP to be bound
+func (d *Driver) bindFloatingIP(ip, instanceID string) error {
+	if err := d.client.BindFloatingIP(ip, instanceID); err != nil {
+		return fmt.Errorf("failed to bind floating IP: %s", logHttp500(err))
+	}
+	if err := d.waitForFloatingIPBinding(ip, instanceID, true); err != nil {
+		return fmt.Errorf("failed to wait for floating IP to be bound: %s", logHttp500(err))
+	}
+	return nil
+}
+
+// MoveFloatingIP unbinds floating IP from one instance and binds it to another one.
+// If binding to the new instance fails, the IP is bound back to the original instance
+func (d *Driver) MoveFloatingIP(ip, fromInstanceID, toInstanceID string) error {
+	if err := d.initComputeV2(); err != nil {
+		return err
+	}
+	if err := d.client.UnbindFloatingIP(ip, fromInstanceID); err != nil {
+		return fmt.Errorf("failed to unbind floating IP: %s", logHttp500(err))
+	}
+	if err := d.waitForFloatingIPBinding(ip, fromInstanceID, false); err != nil {
+		return fmt.Errorf("failed to wait for floating IP to be unbound: %s", logHttp500(err))
+	}
+	err := d.bindFloatingIP(ip, toInstanceID)
+	if err == nil {
+		return nil
+	}
+	if rbErr := d.bindFloatingIP(ip, fromInstanceID); rbErr != nil {
+		return multierror.Append(
+			fmt.Errorf("failed to move floating IP to instance `%s`: %s", toInstanceID, err),
+			fmt.Errorf("failed to bind floating IP back to instance `%s`: %s", fromInstanceID, rbErr),
+		)
+	}
+	return fmt.Errorf("failed to move floating IP to instance `%s`, it's bound back to instance `%s`: %s",
+		toInstanceID, fromInstanceID, err)
+}
+
+// DeleteFloatingIP releases elastic IP with given address. Bound IP is unbound first if `force` is set,
+// ErrFloatingIPInUse is returned otherwise. Missing IP is considered to be already deleted
+func (d *Driver) DeleteFloatingIP(ip string, force bool) error {
+	if err := d.initNetwork(); err != nil {
+		return err
+	}
+	eip, err := d.findElasticIP(ip)
+	if err != nil {
+		return fmt.Errorf("failed to find floating IP: %s", logHttp500(err))
+	}
+	if eip == nil {
+		return nil
+	}
+	if eip.PortID != "" {
+		if !force {
+			return fmt.Errorf("%w: `%s` is bound to port `%s`", ErrFloatingIPInUse, ip, eip.PortID)
+		}
+		if err := d.unbindElasticIP(eip.ID); err != nil {
+			return fmt.Errorf("failed to unbind floating IP: %s", logHttp500(err))
+		}
+		if err := d.waitForEIPUnbound(ip); err != nil {
+			return fmt.Errorf("failed to wait for floating IP to be unbound: %s", logHttp500(err))
+		}
+	}
+	if err := d.client.DeleteFloatingIP(ip); err != nil {
+		return fmt.Errorf("failed to delete floating IP: %s", logHttp500(err))
+	}
+	return nil
+}
+
+// unbindElasticIP unbinds elastic IP from its port. `eips.UpdateOpts` omits empty port ID,
+// so the update body is built manually: port ID has to be explicitly set to `null`
+func (d *Driver) unbindElasticIP(eipID string) error {
+	body := map[string]interface{}{
+		"publicip": map[string]interface{}{"port_id": nil},
+	}
+	_, err := d.vpc.Put(d.vpc.ServiceURL(d.vpc.ProjectID, "publicips", eipID), body, nil, &golangsdk.RequestOpts{
+		OkCodes: []int{200},
+	})
+	return err
+}
@@ -0,0 +1,245 @@
+package opentelekomcloud
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/opentelekomcloud-infra/crutch-house/services"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/imageservice/v2/images"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/ims/v2/cloudimages"
+	"github.com/opentelekomcloud/gophertelekomcloud/pagination"
+)
+
+const (
+	imagePlatformProperty     = "__platform"
+	imageArchitectureProperty = "architecture"
+	imageOSVersionProperty    = "__os_version"
+)
+
+// imageSSHUsers maps OS names found in image name or OS version to default SSH users of the images
+var imageSSHUsers = []struct {
+	os   string
+	user string
+}{
+	{"ubuntu", "ubuntu"},
+	{"debian", "debian"},
+	{"centos", "linux"},
+	{"fedora", "linux"},
+	{"opensuse", "linux"},
+	{"suse", "linux"},
+	{"oracle", "linux"},
+	{"euleros", "linux"},
+	{"coreos", "core"},
+}
+
+// imageSSHUser returns default SSH user of the image based on its name and OS version,
+// default user is returned for unknown images
+func imageSSHUser(image *images.Image) string {
+	description := strings.ToLower(image.Name)
+	if version, ok := image.Properties[imageOSVersionProperty].(string); ok {
+		description += " " + strings.ToLower(version)
+	}
+	for _, known := range imageSSHUsers {
+		if strings.Contains(description, known.os) {
+			return known.user
+		}
+	}
+	return defaultSSHUser
+}
+
+// setImageSSHUser sets SSH user matching the image if it's not set explicitly
+func (d *Driver) setImageSSHUser() error {
+	if d.SSHUser != "" {
+		return nil
+	}
+	image, err := d.getImage()
+	if err != nil {
+		return err
+	}
+	d.SSHUser = imageSSHUser(image)
+	d.logger().Debugf("Using SSH user `%s` for image `%s`", d.SSHUser, image.Name)
+	return nil
+}
+
+// ImageFilter is used to filter images returned by ListImages, empty fields are ignored
+type ImageFilter struct {
+	// OS is image OS family, e.g. `Ubuntu` or `Debian`
+	OS string
+	// Architecture is image architecture, e.g. `x86_64`
+	Architecture string
+	// Visibility is image visibility, e.g. `public` or `private`
+	Visibility images.ImageVisibility
+}
+
+// Image contains image details
+type Image struct {
+	ID           string
+	Name         string
+	OS           string
+	Architecture string
+	// MinDisk in GB
+	MinDisk int
+	// MinRAM in MB
+	MinRAM int
+}
+
+func imageProperty(image images.Image, key string) string {
+	value, _ := image.Properties[key].(string)
+	return value
+}
+
+// ListImages returns images matching given filter
+func (d *Driver) ListImages(filter ImageFilter) ([]Image, error) {
+	if err := d.initImage(); err != nil {
+		return nil, err
+	}
+	var result []Image
+	opts := images.ListOpts{Visibility: filter.Visibility}
+	err := images.List(d.image, opts).EachPage(func(page pagination.Page) (bool, error) {
+		imageList, err := images.ExtractImages(page)
+		if err != nil {
+			return false, err
+		}
+		for _, image := range imageList {
+			platform := imageProperty(image, imagePlatformProperty)
+			architecture := imageProperty(image, imageArchitectureProperty)
+			if filter.OS != "" && !strings.EqualFold(filter.OS, platform) {
+				continue
+			}
+			if filter.Architecture != "" && !strings.EqualFold(filter.Architecture, architecture) {
+				continue
+			}
+			result = append(result, Image{
+				ID:           image.ID,
+				Name:         image.Name,
+				OS:           platform,
+				Architecture: architecture,
+				MinDisk:      image.MinDiskGigabytes,
+				MinRAM:       image.MinRAMMegabytes,
+			})
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %s", logHttp500(err))
+	}
+	return result, nil
+}
+
+// newestImage returns the latest created image with exactly matching name and
+// number of images sharing the name
+func newestImage(imageList []images.Image, name string) (*images.Image, int) {
+	var newest *images.Image
+	count := 0
+	for i, image := range imageList {
+		if image.Name != name {
+			continue
+		}
+		count++
+		if newest == nil || image.CreatedAt.After(newest.CreatedAt) {
+			newest = &imageList[i]
+		}
+	}
+	return newest, count
+}
+
+// findImage resolves image ID by name searching private images of the project first
+// and falling back to public images, found ID is cached in the lookup cache
+func (d *Driver) findImage(name string) (string, error) {
+	return d.cachedLookup("image", name, func() (string, error) {
+		return d.lookupImage(name)
+	})
+}
+
+func (d *Driver) lookupImage(name string) (string, error) {
+	if err := d.initImage(); err != nil {
+		return "", err
+	}
+	for _, visibility := range []images.ImageVisibility{images.ImageVisibilityPrivate, images.ImageVisibilityPublic} {
+		opts := images.ListOpts{Name: name, Visibility: visibility}
+		pages, err := images.List(d.image, opts).AllPages()
+		if err != nil {
+			return "", err
+		}
+		imageList, err := images.ExtractImages(pages)
+		if err != nil {
+			return "", err
+		}
+		image, count := newestImage(imageList, name)
+		if image == nil {
+			continue
+		}
+		if count > 1 {
+			d.logger().Warnf("Found %d %s images named `%s`, using the newest one: %s", count, visibility, name, image.ID)
+		}
+		return image.ID, nil
+	}
+	return "", nil
+}
+
+// CreateImageFromInstance creates private image from the instance system disk, waits for it
+// to become active and returns its ID. If `StopBeforeImage` is set, running instance is stopped
+// for consistent image and started again after the image is created
+func (d *Driver) CreateImageFromInstance(instanceID, imageName string) (string, error) {
+	if err := d.initComputeV2(); err != nil {
+		return "", err
+	}
+	if err := d.initImage(); err != nil {
+		return "", err
+	}
+	stopped := false
+	if d.StopBeforeImage {
+		instance, err := d.GetInstanceStatus(instanceID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get instance status: %s", logHttp500(err))
+		}
+		if instance.Status == services.InstanceStatusRunning {
+			if err := d.client.StopInstance(instanceID); err != nil {
+				return "", fmt.Errorf("failed to stop instance: %s", logHttp500(err))
+			}
+			stopped = true
+			if err := d.waitForInstanceStatus(instanceID, services.InstanceStatusStopped); err != nil {
+				return "", fmt.Errorf("failed to wait for instance status: %s", logHttp500(err))
+			}
+		}
+	}
+
+	imageID, err := d.createImage(instanceID, imageName)
+
+	if stopped {
+		if startErr := d.client.StartInstance(instanceID); startErr != nil {
+			d.logger().Errorf("failed to start instance after image creation: %s", logHttp500(startErr))
+			if err == nil {
+				err = fmt.Errorf("failed to start instance: %s", logHttp500(startErr))
+			}
+		} else if waitErr := d.waitForInstanceStatus(instanceID, services.InstanceStatusRunning); waitErr != nil && err == nil {
+			err = fmt.Errorf("failed to wait for instance status: %s", logHttp500(waitErr))
+		}
+	}
+	return imageID, err
+}
+
+func (d *Driver) createImage(instanceID, imageName string) (string, error) {
+	job, err := cloudimages.CreateImageByServer(d.image, cloudimages.CreateByServerOpts{
+		Name:       imageName,
+		InstanceId: instanceID,
+	}).ExtractJobResponse()
+	if err != nil {
+		return "", fmt.Errorf("failed to create image: %s", logHttp500(err))
+	}
+	if err := cloudimages.WaitForJobSuccess(d.image, d.waitTimeout(), job.JobID); err != nil {
+		return "", fmt.Errorf("failed to wait for image creation: %s", logHttp500(err))
+	}
+	entity, err := cloudimages.GetJobEntity(d.image, job.JobID, "image_id")
+	if err != nil {
+		return "", fmt.Errorf("failed to get created image ID: %s", logHttp500(err))
+	}
+	imageID, ok := entity.(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected conversion error: can't convert image ID to string")
+	}
+	if err := d.waitForImageStatus(imageID, images.ImageStatusActive); err != nil {
+		return "", fmt.Errorf("failed to wait for image status: %s", logHttp500(err))
+	}
+	return imageID, nil
+}
@@ -0,0 +1,150 @@
+package opentelekomcloud
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/common/tags"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/servers"
+)
+
+// Instance contains instance details together with extended status attributes
+type Instance struct {
+	servers.Server
+	// AvailabilityZone is a name of the availability zone of the instance
+	AvailabilityZone string
+	// VMState is a state of the instance VM, e.g. `active` or `stopped`
+	VMState string
+	// PowerState is a power state of the instance VM: 0 - no state, 1 - running, 3 - paused, 4 - shutdown
+	PowerState int
+	// TaskState is a task currently performed on the instance, e.g. `powering-on`, empty if there is no task
+	TaskState string
+}
+
+// GetInstanceStatus returns instance details, ErrInstanceNotFound is returned if instance doesn't exist
+func (d *Driver) GetInstanceStatus(instanceID string) (*Instance, error) {
+	if err := d.initComputeV2(); err != nil {
+		return nil, err
+	}
+	result := servers.Get(d.compute, instanceID)
+	server, err := result.Extract()
+	if err != nil {
+		return nil, wrapNotFound(err, ErrInstanceNotFound)
+	}
+	// Server has custom JSON unmarshalling, so extended attributes are extracted separately
+	var ext struct {
+		AvailabilityZone string `json:"OS-EXT-AZ:availability_zone"`
+		VMState          string `json:"OS-EXT-STS:vm_state"`
+		PowerState       int    `json:"OS-EXT-STS:power_state"`
+		TaskState        string `json:"OS-EXT-STS:task_state"`
+	}
+	if err := result.ExtractInto(&ext); err != nil {
+		return nil, fmt.Errorf("failed to extract instance status: %s", err)
+	}
+	return &Instance{
+		Server:           *server,
+		AvailabilityZone: ext.AvailabilityZone,
+		VMState:          ext.VMState,
+		PowerState:       ext.PowerState,
+		TaskState:        ext.TaskState,
+	}, nil
+}
+
+// FindInstance returns ID of the instance with given name, ErrInstanceNotFound is returned if there is no such instance
+func (d *Driver) FindInstance(name string) (string, error) {
+	instanceID, err := d.findInstance(name)
+	if err != nil {
+		return "", err
+	}
+	if instanceID == "" {
+		return "", fmt.Errorf("%w: no instance with name `%s`", ErrInstanceNotFound, name)
+	}
+	return instanceID, nil
+}
+
+// InstanceFilter contains filters of ListInstances, empty filter matches all instances of the project
+type InstanceFilter struct {
+	// NamePrefix matches instances with name starting with the prefix
+	NamePrefix string
+	// Tags matches instances having all the given tags
+	Tags []tags.ResourceTag
+}
+
+type listedInstance struct {
+	ID               string                 `json:"id"`
+	Name             string                 `json:"name"`
+	Status           string                 `json:"status"`
+	Addresses        map[string]interface{} `json:"addresses"`
+	AvailabilityZone string                 `json:"OS-EXT-AZ:availability_zone"`
+	Tags             []string               `json:"tags"`
+}
+
+// matches reports if the instance matches the filter
+func (f InstanceFilter) matches(instance listedInstance) bool {
+	if !strings.HasPrefix(instance.Name, f.NamePrefix) {
+		return false
+	}
+	instanceTags := make(map[string]bool, len(instance.Tags))
+	for _, tag := range instance.Tags {
+		instanceTags[tag] = true
+	}
+	for _, tag := range f.Tags {
+		if !instanceTags[tag.Key+"="+tag.Value] {
+			return false
+		}
+	}
+	return true
+}
+
+// query returns server-side filters of the ECS list: name filter matches substring
+// and only single tag can be used, so the result still has to be checked with `matches`
+func (f InstanceFilter) query() url.Values {
+	query := url.Values{}
+	if f.NamePrefix != "" {
+		query.Set("name", f.NamePrefix)
+	}
+	if len(f.Tags) > 0 {
+		query.Set("tags", f.Tags[0].Key+"="+f.Tags[0].Value)
+	}
+	return query
+}
+
+// ListInstances returns instances of the project matching the filter.
+// ECS list is paginated by page number, so all pages are requested until incomplete page is returned
+func (d *Driver) ListInstances(filter InstanceFilter) ([]Instance, error) {
+	if err := d.initComputeV1(); err != nil {
+		return nil, err
+	}
+	query := filter.query()
+	query.Set("limit", strconv.Itoa(listPageLimit))
+	listURL := d.ecs.ServiceURL("cloudservers", "detail")
+	var result []Instance
+	for page := 1; ; page++ {
+		query.Set("offset", strconv.Itoa(page))
+		var body struct {
+			Servers []listedInstance `json:"servers"`
+		}
+		if _, err := d.ecs.Get(listURL+"?"+query.Encode(), &body, nil); err != nil {
+			return nil, fmt.Errorf("failed to list instances: %s", logHttp500(err))
+		}
+		for _, instance := range body.Servers {
+			if !filter.matches(instance) {
+				continue
+			}
+			result = append(result, Instance{
+				Server: servers.Server{
+					ID:        instance.ID,
+					Name:      instance.Name,
+					Status:    instance.Status,
+					Addresses: instance.Addresses,
+				},
+				AvailabilityZone: instance.AvailabilityZone,
+			})
+		}
+		if len(body.Servers) < listPageLimit {
+			return result, nil
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package opentelekomcloud
+
+import (
+	"fmt"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// publicKeyFromPrivate returns public key of the private key in authorized_keys format
+func publicKeyFromPrivate(privateKey []byte) ([]byte, error) {
+	signer, err := ssh.ParsePrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %s", err)
+	}
+	return ssh.MarshalAuthorizedKey(signer.PublicKey()), nil
+}
+
+// keyPairPublicKey returns public key of the existing key pair, so it's used as is and never deleted.
+// If the key pair doesn't exist, it's created from the private key and removed together with the machine
+func (d *Driver) keyPairPublicKey(privateKey []byte) ([]byte, error) {
+	publicKey, err := d.client.FindKeyPair(d.KeyPairName.Value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find key pair: %s", logHttp500(err))
+	}
+	if publicKey != "" {
+		return []byte(publicKey), nil
+	}
+	generated, err := publicKeyFromPrivate(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	d.logger().Infof("Key pair `%s` doesn't exist, creating it from the private key", d.KeyPairName.Value)
+	d.KeyPairName.DriverManaged = true
+	if _, err := d.createKeyPair(generated); err != nil {
+		return nil, err
+	}
+	return generated, nil
+}
+
+// deleteKeyPair deletes key pair created by the driver and waits until it can't be found anymore
+func (d *Driver) deleteKeyPair() error {
+	if !d.KeyPairName.DriverManaged || d.KeyPairName.Value == "" {
+		return nil
+	}
+	if err := d.client.DeleteKeyPair(d.KeyPairName.Value); err != nil {
+		return fmt.Errorf("failed to delete key pair: %s", logHttp500(err))
+	}
+	err := d.waitFor(func() (bool, error) {
+		publicKey, err := d.client.FindKeyPair(d.KeyPairName.Value)
+		if err != nil {
+			return false, err
+		}
+		return publicKey == "", nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to wait for key pair deletion: %s", logHttp500(err))
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package opentelekomcloud
+
+import (
+	"github.com/docker/machine/libmachine/log"
+)
+
+// Logger is used by the driver for all its messages, so the driver can be integrated
+// with any logging library when used outside of docker-machine
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// machineLogger writes messages with docker-machine log package
+type machineLogger struct{}
+
+func (machineLogger) Debugf(format string, args ...interface{}) { log.Debugf(format, args...) }
+func (machineLogger) Infof(format string, args ...interface{})  { log.Infof(format, args...) }
+func (machineLogger) Warnf(format string, args ...interface{})  { log.Warnf(format, args...) }
+func (machineLogger) Errorf(format string, args ...interface{}) { log.Errorf(format, args...) }
+
+// orDefaultLogger returns given logger or docker-machine logger if it's nil
+func orDefaultLogger(logger Logger) Logger {
+	if logger == nil {
+		return machineLogger{}
+	}
+	return logger
+}
+
+// logger returns logger of the driver
+func (d *Driver) logger() Logger {
+	return orDefaultLogger(d.Logger)
+}
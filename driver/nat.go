@@ -0,0 +1,152 @@
+package opentelekomcloud
+
+import (
+	"fmt"
+
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/extensions/natgateways"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/extensions/snatrules"
+)
+
+const (
+	// natGatewaySpecSmall is the smallest NAT gateway size supporting up to 10000 connections
+	natGatewaySpecSmall = "1"
+	natStatusActive     = "ACTIVE"
+)
+
+func (d *Driver) initNat() error {
+	if d.nat != nil {
+		return nil
+	}
+	pc, err := d.providerClient()
+	if err != nil {
+		return err
+	}
+	nat, err := openstack.NewNatV2(pc, d.endpointOpts)
+	if err != nil {
+		return fmt.Errorf("failed to initialize NAT service: %s", logHttp500(err))
+	}
+	d.nat = nat
+	return nil
+}
+
+// CreateNatGateway creates NAT gateway for the subnet of the VPC and waits for it to become active
+func (d *Driver) CreateNatGateway(vpcID, subnetID string) (string, error) {
+	if err := d.initNat(); err != nil {
+		return "", err
+	}
+	gateway, err := natgateways.Create(d.nat, natgateways.CreateOpts{
+		Name:              fmt.Sprintf("%s-nat", d.MachineName),
+		Spec:              natGatewaySpecSmall,
+		RouterID:          vpcID,
+		InternalNetworkID: subnetID,
+	}).Extract()
+	if err != nil {
+		return "", fmt.Errorf("failed to create NAT gateway: %s", logHttp500(err))
+	}
+	if err := d.WaitForNatGatewayStatus(gateway.ID, natStatusActive); err != nil {
+		return gateway.ID, fmt.Errorf("failed to wait for NAT gateway to be active: %s", logHttp500(err))
+	}
+	return gateway.ID, nil
+}
+
+// DeleteNatGateway deletes NAT gateway and waits for the deletion to finish
+func (d *Driver) DeleteNatGateway(gatewayID string) error {
+	if err := d.initNat(); err != nil {
+		return err
+	}
+	if err := natgateways.Delete(d.nat, gatewayID).ExtractErr(); err != nil {
+		return fmt.Errorf("failed to delete NAT gateway: %s", logHttp500(err))
+	}
+	if err := d.WaitForNatGatewayDeleted(gatewayID); err != nil {
+		return fmt.Errorf("failed to wait for NAT gateway deletion: %s", logHttp500(err))
+	}
+	return nil
+}
+
+// CreateSnatRule creates SNAT rule translating addresses of the machine subnet
+// to the elastic IP and waits for the rule to become active
+func (d *Driver) CreateSnatRule(gatewayID, eipID string) (string, error) {
+	if err := d.initNat(); err != nil {
+		return "", err
+	}
+	rule, err := snatrules.Create(d.nat, snatrules.CreateOpts{
+		NatGatewayID: gatewayID,
+		NetworkID:    d.SubnetID.Value,
+		FloatingIPID: eipID,
+	}).Extract()
+	if err != nil {
+		return "", fmt.Errorf("failed to create SNAT rule: %s", logHttp500(err))
+	}
+	if err := d.WaitForSnatRuleStatus(rule.ID, natStatusActive); err != nil {
+		return rule.ID, fmt.Errorf("failed to wait for SNAT rule to be active: %s", logHttp500(err))
+	}
+	return rule.ID, nil
+}
+
+// DeleteSnatRule deletes SNAT rule and waits for the deletion to finish
+func (d *Driver) DeleteSnatRule(ruleID string) error {
+	if err := d.initNat(); err != nil {
+		return err
+	}
+	if err := snatrules.Delete(d.nat, ruleID).ExtractErr(); err != nil {
+		return fmt.Errorf("failed to delete SNAT rule: %s", logHttp500(err))
+	}
+	if err := d.WaitForSnatRuleDeleted(ruleID); err != nil {
+		return fmt.Errorf("failed to wait for SNAT rule deletion: %s", logHttp500(err))
+	}
+	return nil
+}
+
+// createNatGateway creates NAT gateway with SNAT rule using new elastic IP,
+// so instances in the subnet can reach the internet without own elastic IPs
+func (d *Driver) createNatGateway() error {
+	if !d.UseNatGateway || d.NatGatewayID != "" {
+		return nil
+	}
+	eip, err := d.client.CreateEIP(d.eipConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create NAT elastic IP: %s", logHttp500(err))
+	}
+	d.NatElasticIP = eip.PublicAddress
+	if err := d.waitForEIPActive(eip.ID); err != nil {
+		return fmt.Errorf("failed to wait for NAT elastic IP to be active: %s", logHttp500(err))
+	}
+	if err := d.tagResource(tagResourceEIP, eip.ID); err != nil {
+		return err
+	}
+	gatewayID, err := d.CreateNatGateway(d.VpcID.Value, d.SubnetID.Value)
+	d.NatGatewayID = gatewayID
+	if err != nil {
+		return err
+	}
+	ruleID, err := d.CreateSnatRule(gatewayID, eip.ID)
+	d.SnatRuleID = ruleID
+	return err
+}
+
+// deleteNatGateway removes SNAT rule, NAT gateway and its elastic IP created by the driver
+func (d *Driver) deleteNatGateway() error {
+	if d.SnatRuleID != "" {
+		if err := d.DeleteSnatRule(d.SnatRuleID); err != nil {
+			return err
+		}
+		d.SnatRuleID = ""
+	}
+	if d.NatGatewayID != "" {
+		if err := d.DeleteNatGateway(d.NatGatewayID); err != nil {
+			return err
+		}
+		d.NatGatewayID = ""
+	}
+	if d.NatElasticIP != "" {
+		if err := d.client.DeleteFloatingIP(d.NatElasticIP); err != nil {
+			return fmt.Errorf("failed to delete NAT elastic IP: %s", logHttp500(err))
+		}
+		if err := d.waitForEIPDeleted(d.NatElasticIP); err != nil {
+			return fmt.Errorf("failed to wait for NAT elastic IP deletion: %s", logHttp500(err))
+		}
+		d.NatElasticIP = ""
+	}
+	return nil
+}
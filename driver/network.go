@@ -1,12 +1,38 @@
 package opentelekomcloud
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
+	"sort"
 
 	"github.com/opentelekomcloud-infra/crutch-house/services"
 	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/ecs/v1/cloudservers"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v1/eips"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v1/subnets"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/ports"
+	"github.com/opentelekomcloud/gophertelekomcloud/pagination"
 )
 
+// subnetCreateOpts extends subnet creation options with IPv6 support
+type subnetCreateOpts struct {
+	subnets.CreateOpts
+	IPv6Enable bool
+}
+
+func (opts subnetCreateOpts) ToSubnetCreateMap() (map[string]interface{}, error) {
+	b, err := opts.CreateOpts.ToSubnetCreateMap()
+	if err != nil {
+		return nil, err
+	}
+	if opts.IPv6Enable {
+		b["subnet"].(map[string]interface{})["ipv6_enable"] = true
+	}
+	return b, nil
+}
+
 func (d *Driver) initNetwork() error {
 	if err := d.Authenticate(); err != nil {
 		return fmt.Errorf("failed to authenticate: %s", logHttp500(err))
@@ -14,6 +40,95 @@ func (d *Driver) initNetwork() error {
 	if err := d.client.InitVPC(); err != nil {
 		return fmt.Errorf("failed to initialize VPCv1 service: %s", logHttp500(err))
 	}
+	if d.vpc != nil {
+		return nil
+	}
+	vpc, err := d.client.NewServiceClient("vpc")
+	if err != nil {
+		return fmt.Errorf("failed to initialize VPCv1 service: %s", logHttp500(err))
+	}
+	d.vpc = vpc
+	network, err := d.client.NewServiceClient("network")
+	if err != nil {
+		return fmt.Errorf("failed to initialize Network v2 service: %s", logHttp500(err))
+	}
+	d.network = network
+	return nil
+}
+
+// validateVPC checks that VPC with given ID exists
+func (d *Driver) validateVPC() error {
+	if d.VpcID.Value == "" {
+		return nil
+	}
+	if _, err := d.client.GetVPCDetails(d.VpcID.Value); err != nil {
+		if _, ok := err.(golangsdk.ErrDefault404); ok {
+			return fmt.Errorf(notFoundByID, "VPC", d.VpcID.Value)
+		}
+		return fmt.Errorf("failed to get VPC details: %s", logHttp500(err))
+	}
+	return nil
+}
+
+// validateSubnet checks that subnet with given ID exists and belongs to the used VPC
+func (d *Driver) validateSubnet(subnetID string) error {
+	subnet, err := d.client.GetSubnetStatus(subnetID)
+	if err != nil {
+		if _, ok := err.(golangsdk.ErrDefault404); ok {
+			return fmt.Errorf(notFoundByID, "subnet", subnetID)
+		}
+		return fmt.Errorf("failed to get subnet details: %s", logHttp500(err))
+	}
+	if d.VpcID.Value == "" {
+		// use VPC of existing subnet
+		d.VpcID = managedSting{Value: subnet.VPC_ID}
+		return nil
+	}
+	if subnet.VPC_ID != d.VpcID.Value {
+		return fmt.Errorf("subnet `%s` doesn't belong to VPC `%s`", subnetID, d.VpcID.Value)
+	}
+	return nil
+}
+
+// validatePrivateIP checks that private IP belongs to the primary subnet and is not used yet
+func (d *Driver) validatePrivateIP() error {
+	if d.PrivateIP == "" {
+		return nil
+	}
+	cidr := d.SubnetCIDR
+	if d.SubnetID.Value != "" {
+		subnet, err := d.client.GetSubnetStatus(d.SubnetID.Value)
+		if err != nil {
+			return fmt.Errorf("failed to get subnet details: %s", logHttp500(err))
+		}
+		cidr = subnet.CIDR
+	}
+	_, subnetNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return fmt.Errorf("invalid subnet CIDR `%s`: %s", cidr, err)
+	}
+	if !subnetNet.Contains(net.ParseIP(d.PrivateIP)) {
+		return fmt.Errorf("private IP `%s` doesn't belong to subnet CIDR `%s`", d.PrivateIP, cidr)
+	}
+	if d.SubnetID.Value == "" {
+		// subnet will be created, so the address is free
+		return nil
+	}
+	pages, err := ports.List(d.network, ports.ListOpts{NetworkID: d.SubnetID.Value}).AllPages()
+	if err != nil {
+		return fmt.Errorf("failed to list subnet ports: %s", logHttp500(err))
+	}
+	portList, err := ports.ExtractPorts(pages)
+	if err != nil {
+		return fmt.Errorf("failed to extract subnet ports: %s", err)
+	}
+	for _, port := range portList {
+		for _, fixedIP := range port.FixedIPs {
+			if fixedIP.IPAddress == d.PrivateIP {
+				return fmt.Errorf("private IP `%s` is already used by port `%s`", d.PrivateIP, port.ID)
+			}
+		}
+	}
 	return nil
 }
 
@@ -29,9 +144,12 @@ func (d *Driver) createVPC() error {
 		Value:         vpc.ID,
 		DriverManaged: true,
 	}
-	if err := d.client.WaitForVPCStatus(d.VpcID.Value, "OK"); err != nil {
+	if err := d.waitForVPCStatus(d.VpcID.Value, "OK"); err != nil {
 		return fmt.Errorf("fail waiting for VPC status `OK`: %s", logHttp500(err))
 	}
+	if err := d.tagResource(tagResourceVPC, d.VpcID.Value); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -39,7 +157,23 @@ func (d *Driver) createSubnet() error {
 	if d.SubnetID.Value != "" {
 		return nil
 	}
-	subnet, err := d.client.CreateSubnet(d.VpcID.Value, d.SubnetName)
+	if !d.VpcID.DriverManaged {
+		// existing VPC may still be in creation
+		if err := d.waitForVPCStatus(d.VpcID.Value, "OK"); err != nil {
+			return fmt.Errorf("fail waiting for VPC status `OK`: %s", logHttp500(err))
+		}
+	}
+	opts, err := d.subnetCreateOpts()
+	if err != nil {
+		return err
+	}
+	var subnet *subnets.Subnet
+	if d.defaultSubnet() {
+		// default subnet is created by the client library using its default DNS servers
+		subnet, err = d.client.CreateSubnet(d.VpcID.Value, d.SubnetName)
+	} else {
+		subnet, err = subnets.Create(d.vpc, opts).Extract()
+	}
 	if err != nil {
 		return fmt.Errorf("fail creating subnet: %s", logHttp500(err))
 	}
@@ -47,60 +181,372 @@ func (d *Driver) createSubnet() error {
 		Value:         subnet.ID,
 		DriverManaged: true,
 	}
-	if err := d.client.WaitForSubnetStatus(d.SubnetID.Value, "ACTIVE"); err != nil {
+	timeout := d.waitTimeout()
+	if d.IPv6 {
+		// dual-stack subnets can take longer to become active
+		timeout *= 2
+	}
+	if err := d.waitForSubnetStatus(d.SubnetID.Value, "ACTIVE", timeout); err != nil {
 		return fmt.Errorf("fail waiting for subnet status `ACTIVE`: %s", logHttp500(err))
 	}
+	if err := d.tagResource(tagResourceSubnet, d.SubnetID.Value); err != nil {
+		return err
+	}
+	return nil
+}
+
+// checkSubnetCIDRConflict checks that the CIDR doesn't overlap CIDR of any existing subnet in the VPC,
+// ErrSubnetCIDRConflict is returned naming the conflicting subnet otherwise
+func (d *Driver) checkSubnetCIDRConflict(vpcID string, cidr *net.IPNet) error {
+	query := url.Values{"vpc_id": {vpcID}}
+	subnetList, err := listAll(d.vpc, d.vpc.ServiceURL(d.vpc.ProjectID, "subnets"), query, "subnets")
+	if err != nil {
+		return fmt.Errorf("failed to list VPC subnets: %s", logHttp500(err))
+	}
+	for _, subnet := range subnetList {
+		_, existing, err := net.ParseCIDR(subnet.CIDR)
+		if err != nil {
+			continue
+		}
+		if existing.Contains(cidr.IP) || cidr.Contains(existing.IP) {
+			return fmt.Errorf("%w: subnet CIDR `%s` overlaps CIDR `%s` of subnet `%s` (%s)",
+				ErrSubnetCIDRConflict, cidr, existing, subnet.Name, subnet.ID)
+		}
+	}
 	return nil
 }
 
+// defaultSubnet reports if the subnet is created with default CIDR, gateway and DNS servers
+func (d *Driver) defaultSubnet() bool {
+	return d.SubnetCIDR == defaultSubnetCIDR && d.SubnetGateway == "" && len(d.SubnetDNSServers) == 0 && !d.IPv6
+}
+
+// subnetCreateOpts builds subnet creation options checking that subnet CIDR fits in VPC CIDR
+func (d *Driver) subnetCreateOpts() (*subnetCreateOpts, error) {
+	_, subnetNet, err := net.ParseCIDR(d.SubnetCIDR)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subnet CIDR `%s`: %s", d.SubnetCIDR, err)
+	}
+	vpc, err := d.client.GetVPCDetails(d.VpcID.Value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VPC details: %s", logHttp500(err))
+	}
+	_, vpcNet, err := net.ParseCIDR(vpc.CIDR)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VPC CIDR `%s`: %s", vpc.CIDR, err)
+	}
+	if !cidrContains(vpcNet, subnetNet) {
+		return nil, fmt.Errorf("subnet CIDR `%s` doesn't fit in VPC CIDR `%s`", d.SubnetCIDR, vpc.CIDR)
+	}
+	if err := d.checkSubnetCIDRConflict(d.VpcID.Value, subnetNet); err != nil {
+		return nil, err
+	}
+	gateway := d.SubnetGateway
+	if gateway == "" {
+		gateway = firstAddress(subnetNet).String()
+	} else if ip := net.ParseIP(gateway); ip == nil || !subnetNet.Contains(ip) {
+		return nil, fmt.Errorf("gateway IP `%s` doesn't belong to subnet CIDR `%s`", gateway, d.SubnetCIDR)
+	}
+	return &subnetCreateOpts{
+		CreateOpts: subnets.CreateOpts{
+			VPC_ID:     d.VpcID.Value,
+			Name:       d.SubnetName,
+			CIDR:       subnetNet.String(),
+			DnsList:    d.SubnetDNSServers,
+			GatewayIP:  gateway,
+			EnableDHCP: true,
+		},
+		IPv6Enable: d.IPv6,
+	}, nil
+}
+
 func (d *Driver) createDefaultGroup() error {
 	if d.ManagedSecurityGroupID != "" || d.ManagedSecurityGroup == "" {
 		return nil
 	}
-	sg, err := d.client.CreateSecurityGroup(d.ManagedSecurityGroup,
-		services.PortRange{From: d.SSHPort},
-		services.PortRange{From: dockerPort},
-	)
+	var ports []services.PortRange
+	if len(d.SourceCIDRs) == 0 {
+		ports = []services.PortRange{{From: d.SSHPort}, {From: dockerPort}}
+	}
+	// group name is used as a prefix, so groups of machines created in parallel are distinguishable
+	name, err := uniqueName(d.ManagedSecurityGroup)
+	if err != nil {
+		return err
+	}
+	sg, err := d.client.CreateSecurityGroup(name, ports...)
 	if err != nil {
 		return fmt.Errorf("fail creating default security group: %s", logHttp500(err))
 	}
+	d.ManagedSecurityGroup = name
 	d.ManagedSecurityGroupID = sg.ID
+	if err := d.addIngressRules(sg.ID); err != nil {
+		return fmt.Errorf("fail configuring default security group: %s", err)
+	}
+	if len(d.EgressRules) > 0 {
+		if err := d.setEgressRules(sg.ID); err != nil {
+			return fmt.Errorf("fail configuring default security group: %s", err)
+		}
+	}
 	return nil
 }
 
-func (d *Driver) createElasticIP() error {
-	if d.ElasticIP.Value == "" {
-		eip, err := d.client.CreateEIP(d.eipConfig)
+// findElasticIP finds elastic IP by its public address
+func (d *Driver) findElasticIP(address string) (*eips.PublicIp, error) {
+	var result *eips.PublicIp
+	err := eips.List(d.vpc, nil).EachPage(func(page pagination.Page) (bool, error) {
+		eipList, err := eips.ExtractEips(page)
 		if err != nil {
-			return fmt.Errorf("failed to create elastic IP: %s", logHttp500(err))
+			return false, err
 		}
-		if err := d.client.WaitForEIPActive(eip.ID); err != nil {
-			return fmt.Errorf("failed to wait for elastic IP to be active: %s", logHttp500(err))
+		for _, eip := range eipList {
+			if eip.PublicAddress == address {
+				result = &eip
+				return false, nil
+			}
 		}
-		d.ElasticIP = managedSting{Value: eip.PublicAddress, DriverManaged: true}
+		return true, nil
+	})
+	return result, err
+}
+
+// validateElasticIP checks that existing elastic IP can be used for the machine
+func (d *Driver) validateElasticIP() error {
+	if d.ElasticIP.Value == "" || d.skipEIPCreation {
+		return nil
 	}
-	if err := d.client.BindFloatingIP(d.ElasticIP.Value, d.InstanceID); err != nil {
-		return fmt.Errorf("failed to bind elastic IP: %s", logHttp500(err))
+	eip, err := d.findElasticIP(d.ElasticIP.Value)
+	if err != nil {
+		return fmt.Errorf("failed to find elastic IP: %s", logHttp500(err))
+	}
+	if eip == nil {
+		return fmt.Errorf("elastic IP `%s` not found", d.ElasticIP.Value)
+	}
+	if eip.PortID != "" {
+		return fmt.Errorf("elastic IP `%s` is already bound to port `%s`", d.ElasticIP.Value, eip.PortID)
 	}
 	return nil
 }
 
+// allocateElasticIP allocates new elastic IP if existing one is not set,
+// the address is bound after the instance is created
+func (d *Driver) allocateElasticIP() error {
+	if d.ElasticIP.Value != "" {
+		return nil
+	}
+	var eip *eips.PublicIp
+	var err error
+	if d.SharedBandwidthID != "" {
+		eip, err = d.createSharedElasticIP()
+	} else {
+		eip, err = d.client.CreateEIP(d.eipConfig)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create elastic IP: %s", logHttp500(err))
+	}
+	d.ElasticIP = managedSting{Value: eip.PublicAddress, DriverManaged: true}
+	if err := d.waitForEIPActive(eip.ID); err != nil {
+		return fmt.Errorf("failed to wait for elastic IP to be active: %s", logHttp500(err))
+	}
+	return d.tagResource(tagResourceEIP, eip.ID)
+}
+
+// instanceAddress is a single address of instance NIC
+type instanceAddress struct {
+	Address string
+	Version int
+	Type    string
+}
+
+const (
+	addressTypeFixed    = "fixed"
+	addressTypeFloating = "floating"
+)
+
+// parseAddresses converts server addresses to flat list, networks are processed in name order
+func parseAddresses(addresses map[string]interface{}) []instanceAddress {
+	networks := make([]string, 0, len(addresses))
+	for network := range addresses {
+		networks = append(networks, network)
+	}
+	sort.Strings(networks)
+	var result []instanceAddress
+	for _, network := range networks {
+		addrList, _ := addresses[network].([]interface{})
+		for _, addr := range addrList {
+			addrDetails, ok := addr.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			address, _ := addrDetails["addr"].(string)
+			if address == "" {
+				continue
+			}
+			version, _ := addrDetails["version"].(float64)
+			addrType, _ := addrDetails["OS-EXT-IPS:type"].(string)
+			if addrType == "" {
+				addrType = addressTypeFixed
+			}
+			result = append(result, instanceAddress{Address: address, Version: int(version), Type: addrType})
+		}
+	}
+	return result
+}
+
+func (d *Driver) instanceAddresses(instanceID string) ([]instanceAddress, error) {
+	instance, err := d.GetInstanceStatus(instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instance (%s) status: %s", instanceID, logHttp500(err))
+	}
+	return parseAddresses(instance.Addresses), nil
+}
+
+// GetInstanceAddresses returns private and floating IP addresses of all instance NICs
+func (d *Driver) GetInstanceAddresses(instanceID string) (private []string, floating []string, err error) {
+	if err := d.initComputeV2(); err != nil {
+		return nil, nil, err
+	}
+	addresses, err := d.instanceAddresses(instanceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, addr := range addresses {
+		if addr.Type == addressTypeFloating {
+			floating = append(floating, addr.Address)
+		} else {
+			private = append(private, addr.Address)
+		}
+	}
+	return private, floating, nil
+}
+
+// GetFloatingIP returns floating IPv4 address of the instance, empty string is returned if there is none
+func (d *Driver) GetFloatingIP(instanceID string) (string, error) {
+	addresses, err := d.instanceAddresses(instanceID)
+	if err != nil {
+		return "", err
+	}
+	for _, addr := range addresses {
+		if addr.Type == addressTypeFloating && addr.Version == 4 {
+			return addr.Address, nil
+		}
+	}
+	return "", nil
+}
+
 func (d *Driver) useLocalIP() error {
-	instance, err := d.client.GetInstanceStatus(d.InstanceID)
+	address, err := d.instancePrivateIP()
 	if err != nil {
-		return fmt.Errorf("failed to get instance (%s) status: %s", d.InstanceID, logHttp500(err))
+		return err
+	}
+	d.ElasticIP = managedSting{
+		Value:         address,
+		DriverManaged: false,
 	}
-	for _, addrPool := range instance.Addresses {
-		addrDetails := addrPool.([]interface{})[0].(map[string]interface{})
-		d.ElasticIP = managedSting{
-			Value:         addrDetails["addr"].(string),
-			DriverManaged: false,
+	return nil
+}
+
+// primaryPort returns instance port in the primary subnet
+func (d *Driver) primaryPort() (*ports.Port, error) {
+	pages, err := ports.List(d.network, ports.ListOpts{
+		DeviceID:  d.InstanceID,
+		NetworkID: d.SubnetID.Value,
+	}).AllPages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list instance ports: %s", logHttp500(err))
+	}
+	portList, err := ports.ExtractPorts(pages)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract instance ports: %s", err)
+	}
+	if len(portList) == 0 {
+		return nil, fmt.Errorf("instance %s has no port in subnet %s", d.InstanceID, d.SubnetID.Value)
+	}
+	return &portList[0], nil
+}
+
+// instancePrivateIP returns IPv4 address of the primary instance NIC
+func (d *Driver) instancePrivateIP() (string, error) {
+	if len(d.AdditionalSubnetIDs) > 0 {
+		port, err := d.primaryPort()
+		if err != nil {
+			return "", err
+		}
+		for _, fixedIP := range port.FixedIPs {
+			if ip := net.ParseIP(fixedIP.IPAddress); ip != nil && ip.To4() != nil {
+				return fixedIP.IPAddress, nil
+			}
+		}
+	} else {
+		addresses, err := d.instanceAddresses(d.InstanceID)
+		if err != nil {
+			return "", err
+		}
+		for _, addr := range addresses {
+			if addr.Type == addressTypeFixed && addr.Version != 6 {
+				return addr.Address, nil
+			}
 		}
+	}
+	return "", fmt.Errorf("no private address is assigned to instance %s", d.InstanceID)
+}
+
+// instancePublicIP returns existing elastic IP to be bound on instance creation,
+// nil is returned if the elastic IP has to be bound after the instance is created
+func (d *Driver) instancePublicIP() *cloudservers.PublicIp {
+	if d.skipEIPCreation || d.ElasticIP.Value == "" {
+		return nil
+	}
+	eip, err := d.findElasticIP(d.ElasticIP.Value)
+	if err != nil || eip == nil {
+		d.logger().Debugf("Elastic IP `%s` will be bound after instance creation: %v", d.ElasticIP.Value, err)
 		return nil
 	}
+	return &cloudservers.PublicIp{Id: eip.ID}
+}
+
+// bindElasticIP binds elastic IP to the primary instance NIC
+func (d *Driver) bindElasticIP() error {
+	if d.eipBoundOnCreate {
+		return nil
+	}
+	if len(d.AdditionalSubnetIDs) == 0 {
+		return d.client.BindFloatingIP(d.ElasticIP.Value, d.InstanceID)
+	}
+	port, err := d.primaryPort()
+	if err != nil {
+		return err
+	}
+	eip, err := d.findElasticIP(d.ElasticIP.Value)
+	if err != nil {
+		return err
+	}
+	if eip == nil {
+		return fmt.Errorf("elastic IP `%s` not found", d.ElasticIP.Value)
+	}
+	if _, err := eips.Update(d.vpc, eip.ID, eips.UpdateOpts{PortID: port.ID}).Extract(); err != nil {
+		return fmt.Errorf("failed to bind elastic IP: %s", logHttp500(err))
+	}
+	if err := d.waitForFloatingIPBinding(d.ElasticIP.Value, d.InstanceID, true); err != nil {
+		return fmt.Errorf("failed to wait for elastic IP to be bound: %s", logHttp500(err))
+	}
 	return nil
 }
 
+// resolveIPv6 sets IPv6 address assigned to the instance
+func (d *Driver) resolveIPv6() error {
+	addresses, err := d.instanceAddresses(d.InstanceID)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addresses {
+		if addr.Version == 6 {
+			d.IPv6Address = addr.Address
+			return nil
+		}
+	}
+	return fmt.Errorf("no IPv6 address is assigned to instance %s", d.InstanceID)
+}
+
 func (d *Driver) deleteVPC() error {
 	if err := d.initNetwork(); err != nil {
 		return err
@@ -110,10 +556,8 @@ func (d *Driver) deleteVPC() error {
 		if err != nil {
 			return fmt.Errorf("failed to delete VPC: %s", logHttp500(err))
 		}
-		err = d.client.WaitForVPCStatus(d.VpcID.Value, "")
-		switch err.(type) {
-		case golangsdk.ErrDefault404:
-		default:
+		err = d.waitForVPCStatus(d.VpcID.Value, "")
+		if !errors.Is(err, ErrVPCNotFound) {
 			return fmt.Errorf("failed to wait for VPC status after deletion: %s", logHttp500(err))
 		}
 	}
@@ -129,10 +573,8 @@ func (d *Driver) deleteSubnet() error {
 		if err != nil {
 			return fmt.Errorf("failed to delete subnet: %s", logHttp500(err))
 		}
-		err = d.client.WaitForSubnetStatus(d.SubnetID.Value, "")
-		switch err.(type) {
-		case golangsdk.ErrDefault404:
-		default:
+		err = d.waitForSubnetStatus(d.SubnetID.Value, "", d.waitTimeout())
+		if !errors.Is(err, ErrSubnetNotFound) {
 			return fmt.Errorf("failed to wait for subnet status after deletion: %s", logHttp500(err))
 		}
 	}
@@ -150,7 +592,7 @@ func (d *Driver) deleteSecGroups() error {
 	if err := d.client.DeleteSecurityGroup(id); err != nil {
 		return fmt.Errorf("failed to delete security group: %s", logHttp500(err))
 	}
-	if err := d.client.WaitForGroupDeleted(id); err != nil {
+	if err := d.waitForGroupDeleted(id); err != nil {
 		return fmt.Errorf("failed to wait for security group status after deletion: %s", logHttp500(err))
 	}
 	return nil
@@ -1,17 +1,20 @@
 package opentelekomcloud
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"strconv"
 
 	"github.com/docker/machine/libmachine/drivers"
-	"github.com/docker/machine/libmachine/log"
-	"github.com/docker/machine/libmachine/mcnutils"
 	"github.com/docker/machine/libmachine/state"
 	"github.com/hashicorp/go-multierror"
 	"github.com/opentelekomcloud-infra/crutch-house/services"
+	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
 	"github.com/opentelekomcloud/gophertelekomcloud/openstack"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/common/tags"
 )
 
 type managedSting struct {
@@ -22,48 +25,126 @@ type managedSting struct {
 // Driver for docker-machine
 type Driver struct {
 	*drivers.BaseDriver
-	Cloud                  string       `json:"cloud,omitempty"`
-	AuthURL                string       `json:"auth_url,omitempty"`
-	CACert                 string       `json:"ca_cert,omitempty"`
-	ValidateCert           bool         `json:"validate_cert"`
-	DomainID               string       `json:"domain_id,omitempty"`
-	DomainName             string       `json:"domain_name,omitempty"`
-	Username               string       `json:"username,omitempty"`
-	Password               string       `json:"password,omitempty"`
-	ProjectName            string       `json:"project_name,omitempty"`
-	ProjectID              string       `json:"project_id,omitempty"`
-	Region                 string       `json:"region,omitempty"`
-	AccessKey              string       `json:"access_key,omitempty"`
-	SecretKey              string       `json:"secret_key,omitempty"`
-	AvailabilityZone       string       `json:"-"`
-	EndpointType           string       `json:"endpoint_type,omitempty"`
-	InstanceID             string       `json:"instance_id"`
-	FlavorName             string       `json:"-"`
-	FlavorID               string       `json:"-"`
-	ImageName              string       `json:"-"`
-	KeyPairName            managedSting `json:"key_pair"`
-	VpcName                string       `json:"-"`
-	VpcID                  managedSting `json:"vpc_id"`
-	SubnetName             string       `json:"-"`
-	SubnetID               managedSting `json:"subnet_id"`
-	PrivateKeyFile         string       `json:"private_key"`
-	SecurityGroups         []string     `json:"-"`
-	SecurityGroupIDs       []string     `json:"-"`
-	ServerGroup            string       `json:"-"`
-	ServerGroupID          string       `json:"-"`
-	ManagedSecurityGroup   string       `json:"-"`
-	ManagedSecurityGroupID string       `json:"managed_security_group,omitempty"`
-	ElasticIP              managedSting `json:"eip"`
-	Token                  string       `json:"token,omitempty"`
-	UserDataFile           string       `json:"-"`
-	UserData               []byte       `json:"-"`
-	Tags                   []string     `json:"-"`
-	IPVersion              int          `json:"-"`
+	Cloud                  string             `json:"cloud,omitempty"`
+	AuthURL                string             `json:"auth_url,omitempty"`
+	CACert                 string             `json:"ca_cert,omitempty"`
+	ValidateCert           bool               `json:"validate_cert"`
+	DomainID               string             `json:"domain_id,omitempty"`
+	DomainName             string             `json:"domain_name,omitempty"`
+	Username               string             `json:"username,omitempty"`
+	Password               string             `json:"password,omitempty"`
+	ProjectName            string             `json:"project_name,omitempty"`
+	ProjectID              string             `json:"project_id,omitempty"`
+	Region                 string             `json:"region,omitempty"`
+	AccessKey              string             `json:"access_key,omitempty"`
+	SecretKey              string             `json:"secret_key,omitempty"`
+	AvailabilityZone       string             `json:"availability_zone,omitempty"`
+	AvailabilityZones      []string           `json:"-"`
+	EndpointType           string             `json:"endpoint_type,omitempty"`
+	InstanceID             string             `json:"instance_id"`
+	InstanceName           string             `json:"instance_name,omitempty"`
+	FlavorName             string             `json:"-"`
+	FlavorID               string             `json:"-"`
+	ImageName              string             `json:"-"`
+	KeyPairName            managedSting       `json:"key_pair"`
+	CloudGeneratedKey      bool               `json:"-"`
+	AdminPassword          string             `json:"-"`
+	VpcName                string             `json:"-"`
+	VpcID                  managedSting       `json:"vpc_id"`
+	SubnetName             string             `json:"-"`
+	SubnetID               managedSting       `json:"subnet_id"`
+	AdditionalSubnetIDs    []string           `json:"additional_subnets,omitempty"`
+	PrivateIP              string             `json:"-"`
+	SubnetCIDR             string             `json:"-"`
+	SubnetGateway          string             `json:"-"`
+	SubnetDNSServers       []string           `json:"-"`
+	PrivateKeyFile         string             `json:"private_key"`
+	SecurityGroups         []string           `json:"-"`
+	SecurityGroupIDs       []string           `json:"-"`
+	ServerGroup            string             `json:"-"`
+	ServerGroupID          managedSting       `json:"server_group"`
+	ManagedSecurityGroup   string             `json:"-"`
+	ManagedSecurityGroupID string             `json:"managed_security_group,omitempty"`
+	EgressRules            []secGroupRule     `json:"-"`
+	SourceCIDRs            []string           `json:"-"`
+	OpenPorts              []secGroupRule     `json:"-"`
+	AllowPing              bool               `json:"-"`
+	DisablePortSecurity    bool               `json:"disable_port_security,omitempty"`
+	ElasticIP              managedSting       `json:"eip"`
+	Token                  string             `json:"token,omitempty"`
+	UseTokenCache          bool               `json:"token_cache,omitempty"`
+	AgencyName             string             `json:"agency_name,omitempty"`
+	ComputeEndpoint        string             `json:"compute_endpoint,omitempty"`
+	NetworkEndpoint        string             `json:"network_endpoint,omitempty"`
+	APIProxy               string             `json:"api_proxy,omitempty"`
+	Insecure               bool               `json:"insecure,omitempty"`
+	WaitTimeout            int                `json:"wait_timeout,omitempty"`
+	WaitInterval           int                `json:"wait_interval,omitempty"`
+	SSHGracePeriod         int                `json:"-"`
+	APIRetries             int                `json:"api_retries,omitempty"`
+	APIRetryDelay          int                `json:"api_retry_delay,omitempty"`
+	APIDebug               bool               `json:"api_debug,omitempty"`
+	AgencyDomain           string             `json:"agency_domain,omitempty"`
+	UserDataFile           string             `json:"-"`
+	UserData               []byte             `json:"-"`
+	Tags                   []tags.ResourceTag `json:"-"`
+	Metadata               map[string]string  `json:"-"`
+	IPVersion              int                `json:"-"`
+	IPv6                   bool               `json:"-"`
+	UseIPv6SSH             bool               `json:"use_ipv6_ssh,omitempty"`
+	IPv6Address            string             `json:"ipv6_address,omitempty"`
+	ReuseExisting          bool               `json:"-"`
+	StopBeforeImage        bool               `json:"stop_before_image,omitempty"`
+	ForceRestart           bool               `json:"force_restart,omitempty"`
+	AutoRecovery           bool               `json:"-"`
+	DryRun                 bool               `json:"-"`
+	SkipQuotaCheck         bool               `json:"-"`
+	SkipFlavorZoneCheck    bool               `json:"-"`
+	SkipLookupCache        bool               `json:"-"`
+	UseNatGateway          bool               `json:"-"`
+	NatGatewayID           string             `json:"nat_gateway_id,omitempty"`
+	SnatRuleID             string             `json:"snat_rule_id,omitempty"`
+	NatElasticIP           string             `json:"nat_elastic_ip,omitempty"`
+	Routes                 []vpcRoute         `json:"routes,omitempty"`
+	PeerVpcID              string             `json:"-"`
+	PeerProjectID          string             `json:"-"`
+	PeerRoutes             []string           `json:"-"`
+	PeeringID              string             `json:"peering_id,omitempty"`
+	SharedBandwidthID      string             `json:"shared_bandwidth_id,omitempty"`
+	AttachVolumeID         string             `json:"attach_volume_id,omitempty"`
+	KeepVolumesOnRemove    bool               `json:"keep_volumes_on_remove,omitempty"`
+	BootFromImage          bool               `json:"boot_from_image,omitempty"`
+	BindEIPOnCreate        bool               `json:"-"`
+	DedicatedHostID        string             `json:"-"`
+	BillingMode            string             `json:"billing_mode,omitempty"`
+	PeriodType             string             `json:"-"`
+	PeriodNum              int                `json:"-"`
+	BastionHost            string             `json:"bastion_host,omitempty"`
+	BastionUser            string             `json:"bastion_user,omitempty"`
+	BastionPort            int                `json:"bastion_port,omitempty"`
+	BastionKeyFile         string             `json:"bastion_key,omitempty"`
 	skipEIPCreation        bool
+	eipBoundOnCreate       bool
 
-	RootVolumeOpts *services.DiskOpts `json:"-"`
+	RootVolumeOpts *services.DiskOpts  `json:"-"`
+	DataVolumes    []services.DiskOpts `json:"data_volumes,omitempty"`
+	KMSKeyID       string              `json:"-"`
+	RootVolumeIOPS int                 `json:"-"`
+	Logger         Logger              `json:"-"` // docker-machine log is used if not set
 	eipConfig      *services.ElasticIPOpts
 	client         services.Client
+	vpc            *golangsdk.ServiceClient
+	network        *golangsdk.ServiceClient
+	compute        *golangsdk.ServiceClient
+	ecs            *golangsdk.ServiceClient
+	image          *golangsdk.ServiceClient
+	volume         *golangsdk.ServiceClient
+	nat            *golangsdk.ServiceClient
+	deh            *golangsdk.ServiceClient
+	bss            *golangsdk.ServiceClient
+	endpointOpts   golangsdk.EndpointOpts
+	transport      http.RoundTripper // default transport is used if not set
+	tunnel         *bastionTunnel
 }
 
 // resCreateErr wraps errors happening in createResources
@@ -85,23 +166,67 @@ func (d *Driver) createResources() error {
 	if err := d.resolveIDs(); err != nil {
 		return resCreateErr(err)
 	}
-	if err := d.createVPC(); err != nil {
-		return resCreateErr(err)
-	}
-	if err := d.createSubnet(); err != nil {
-		return resCreateErr(err)
+	// subnet depends on VPC, other resources are independent
+	steps := []creationStep{
+		{name: "network", run: func() error {
+			if err := d.createVPC(); err != nil {
+				return err
+			}
+			if err := d.createSubnet(); err != nil {
+				return err
+			}
+			if err := d.addRoutes(); err != nil {
+				return err
+			}
+			if err := d.createPeering(); err != nil {
+				return err
+			}
+			return d.createNatGateway()
+		}},
+		{name: "security group", run: d.createDefaultGroup},
+		{name: "server group", run: d.createServerGroup},
+		{name: "key pair", run: d.prepareKeyPair},
+	}
+	if !d.skipEIPCreation {
+		steps = append(steps, creationStep{name: "elastic IP", run: d.allocateElasticIP})
+	}
+	return resCreateErr(runParallel(d.logger(), steps))
+}
+
+// createAdoptedResources allocates elastic IP for the adopted instance having no elastic IP bound
+func (d *Driver) createAdoptedResources() error {
+	if d.skipEIPCreation || d.ElasticIP.Value != "" {
+		return nil
 	}
-	if err := d.createDefaultGroup(); err != nil {
+	if err := d.initNetwork(); err != nil {
 		return resCreateErr(err)
 	}
+	return resCreateErr(d.allocateElasticIP())
+}
 
-	return nil
+// prepareKeyPair loads existing key pair or creates new one
+func (d *Driver) prepareKeyPair() error {
+	if d.KeyPairName.Value != "" {
+		return d.loadSSHKey()
+	}
+	if d.passwordOnly() {
+		return d.createLocalSSHKey()
+	}
+	name, err := uniqueName(d.MachineName)
+	if err != nil {
+		return err
+	}
+	d.KeyPairName = managedSting{name, true}
+	return d.createSSHKey()
 }
 
 func (d *Driver) Authenticate() error {
 	if d.client != nil {
 		return nil
 	}
+	if err := d.configureTransport(); err != nil {
+		return err
+	}
 	cloud := &openstack.Cloud{
 		Cloud:        d.Cloud,
 		RegionName:   d.Region,
@@ -119,10 +244,16 @@ func (d *Driver) Authenticate() error {
 			Token:       d.Token,
 		},
 	}
-	// we don't need domain for project-level AK/SK auth
+	// we don't need domain for project-level AK/SK auth,
+	// AK/SK takes precedence over other auth methods
 	if d.AccessKey != "" {
-		cloud.AuthInfo.DomainName = ""
-		cloud.AuthInfo.DomainID = ""
+		if d.AgencyName == "" {
+			cloud.AuthInfo.DomainName = ""
+			cloud.AuthInfo.DomainID = ""
+		}
+		cloud.AuthInfo.Username = ""
+		cloud.AuthInfo.Password = ""
+		cloud.AuthInfo.Token = ""
 	}
 
 	defaultCloud, err := openstack.NewEnv("OS_").Cloud(d.Cloud)
@@ -131,47 +262,215 @@ func (d *Driver) Authenticate() error {
 	}
 	merged, err := mergeClouds(cloud, defaultCloud) // merge given flags with config from configuration files
 	if err != nil {
-		log.Errorf("unable to merge cloud with defaults")
+		d.logger().Errorf("unable to merge cloud with defaults")
 	} else {
 		cloud = merged
 	}
-	d.client = services.NewCloudClient(cloud)
-	if err := d.client.Authenticate(); err != nil {
+	d.endpointOpts = golangsdk.EndpointOpts{
+		Region:       cloud.RegionName,
+		Availability: getAvailability(cloud.EndpointType),
+	}
+	if d.AgencyName != "" {
+		cloud, err = d.agencyCloud(cloud, d.AgencyName, d.AgencyDomain)
+		if err != nil {
+			return fmt.Errorf("failed to authenticate the client: %s", err)
+		}
+	}
+	if d.UseTokenCache {
+		if canCacheToken(cloud) {
+			if err := d.authenticateCached(cloud); err != nil {
+				d.client = nil
+				return fmt.Errorf("failed to authenticate the client: %s", logHttp500(err))
+			}
+			return d.overrideEndpoints()
+		}
+		d.logger().Debugf("Token cache is not used for AK/SK or token authentication")
+	}
+	client, err := d.newCloudClient(cloud)
+	if err != nil {
 		return fmt.Errorf("failed to authenticate the client: %s", logHttp500(err))
 	}
+	d.client = client
+	return d.overrideEndpoints()
+}
+
+// PreCreateCheck validates existing resources given in configuration
+func (d *Driver) PreCreateCheck() error {
+	if err := d.validateInstanceName(); err != nil {
+		return err
+	}
+	if err := d.validateUserData(); err != nil {
+		return err
+	}
+	if d.AdminPassword != "" {
+		if err := validateAdminPassword(d.AdminPassword, d.GetSSHUsername()); err != nil {
+			return err
+		}
+	}
+	if d.passwordOnly() && len(d.UserData) > 0 {
+		// the machine is still provisioned via SSH, so the key is injected using user data
+		return fmt.Errorf("user data can't be used with admin password without key pair: " +
+			"SSH key of the machine is injected via user data, configure key pair to use custom user data")
+	}
+	if d.skipEIPCreation {
+		d.logger().Infof("Floating IP won't be allocated, machine private address " +
+			"has to be reachable from this host (e.g. via VPC peering or VPN)")
+	}
+	if err := validateVolumeType(d.RootVolumeOpts.Type); err != nil {
+		return err
+	}
+	for _, disk := range d.DataVolumes {
+		if err := validateVolumeType(disk.Type); err != nil {
+			return err
+		}
+	}
+	if err := d.initNetwork(); err != nil {
+		return err
+	}
+	if err := d.validateVPC(); err != nil {
+		return err
+	}
+	if d.SubnetID.Value != "" {
+		if err := d.validateSubnet(d.SubnetID.Value); err != nil {
+			return err
+		}
+	}
+	for _, subnetID := range d.AdditionalSubnetIDs {
+		if err := d.validateSubnet(subnetID); err != nil {
+			return err
+		}
+	}
+	if err := d.validatePrivateIP(); err != nil {
+		return err
+	}
+	if err := d.validateElasticIP(); err != nil {
+		return err
+	}
+	if err := d.validateSharedBandwidth(); err != nil {
+		return err
+	}
+	if err := d.initComputeV2(); err != nil {
+		return err
+	}
+	if err := d.validateDedicatedHost(); err != nil {
+		return err
+	}
+	if !d.SkipFlavorZoneCheck {
+		if err := d.validateFlavorZones(); err != nil {
+			return err
+		}
+	}
+	if err := d.checkFlavorRAM(); err != nil {
+		return err
+	}
+	if err := d.initImage(); err != nil {
+		return err
+	}
+	if err := d.validateImageRequirements(); err != nil {
+		return err
+	}
+	if err := d.setImageSSHUser(); err != nil {
+		return err
+	}
+	if err := d.validateAttachVolume(); err != nil {
+		return err
+	}
+	if !d.SkipQuotaCheck {
+		if err := d.checkQuotas(); err != nil {
+			return err
+		}
+	}
+	if d.DryRun {
+		return d.dryRun()
+	}
 	return nil
 }
 
 // Create creates new ECS used for docker-machine
 func (d *Driver) Create() error {
+	return d.CreateContext(context.Background())
+}
+
+// CreateContext creates new ECS used for docker-machine, creation
+// is aborted between steps and while waiting for the instance when the context is done.
+// All resources created by the driver are removed if creation fails
+func (d *Driver) CreateContext(ctx context.Context) error {
 	if err := d.Authenticate(); err != nil {
 		return err
 	}
-	if err := d.createResources(); err != nil {
+	if err := d.create(ctx); err != nil {
+		if rbErr := d.rollbackResources(); rbErr != nil {
+			return multierror.Append(err, rbErr)
+		}
 		return err
 	}
-	if d.KeyPairName.Value != "" {
-		if err := d.loadSSHKey(); err != nil {
+	return nil
+}
+
+// rollbackResources removes resources created by the driver, reused resources are left untouched
+func (d *Driver) rollbackResources() error {
+	d.logger().Infof("Machine creation failed, removing created resources")
+	if err := d.Remove(); err != nil {
+		return fmt.Errorf("failed to remove created resources: %s", err)
+	}
+	return nil
+}
+
+func (d *Driver) create(ctx context.Context) error {
+	adopted := false
+	if d.ReuseExisting {
+		if err := d.initCompute(); err != nil {
+			return err
+		}
+		if err := d.adoptExistingInstance(); err != nil {
+			return err
+		}
+		adopted = d.InstanceID != ""
+	}
+	if adopted {
+		// resources of the adopted instance are loaded from the instance, only missing elastic IP is allocated
+		if err := d.createAdoptedResources(); err != nil {
 			return err
 		}
+		if err := d.WaitForInstanceStatusContext(ctx, d.InstanceID, services.InstanceStatusRunning); err != nil {
+			return fmt.Errorf("failed to wait for instance status: %s", logHttp500(err))
+		}
 	} else {
-		d.KeyPairName = managedSting{
-			fmt.Sprintf("%s-%s", d.MachineName, mcnutils.GenerateRandomID()),
-			true,
+		if err := d.createResources(); err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
 		}
-		if err := d.createSSHKey(); err != nil {
+		if err := d.CreateInstanceContext(ctx); err != nil {
 			return err
 		}
 	}
-	if err := d.createInstance(); err != nil {
+	if err := ctx.Err(); err != nil {
 		return err
 	}
+	if err := d.attachVolume(); err != nil {
+		return err
+	}
+	if d.DisablePortSecurity {
+		if err := d.SetPortSecurity(false); err != nil {
+			return fmt.Errorf("failed to disable port security: %s", err)
+		}
+	}
+	if d.IPv6 {
+		if err := d.resolveIPv6(); err != nil {
+			return err
+		}
+	}
 	if d.skipEIPCreation {
 		if err := d.useLocalIP(); err != nil {
 			return err
 		}
 	} else {
-		if err := d.createElasticIP(); err != nil {
+		if err := d.bindElasticIP(); err != nil {
+			return fmt.Errorf("failed to bind elastic IP: %s", logHttp500(err))
+		}
+		if err := d.waitForSSH(); err != nil {
 			return err
 		}
 	}
@@ -185,43 +484,55 @@ func (d *Driver) Start() error {
 	if err := d.client.StartInstance(d.InstanceID); err != nil {
 		return fmt.Errorf("failed to start instance: %s", err)
 	}
-	if err := d.client.WaitForInstanceStatus(d.InstanceID, services.InstanceStatusRunning); err != nil {
+	if err := d.waitForInstanceStatus(d.InstanceID, services.InstanceStatusRunning); err != nil {
 		return fmt.Errorf("failed to wait for instance status: %s", logHttp500(err))
 	}
 	return nil
 }
 
+// Stop gracefully stops the instance
 func (d *Driver) Stop() error {
-	if err := d.initComputeV2(); err != nil {
-		return err
-	}
-	if err := d.client.StopInstance(d.InstanceID); err != nil {
-		return fmt.Errorf("failed to stop instance: %s", logHttp500(err))
-	}
-	if err := d.client.WaitForInstanceStatus(d.InstanceID, services.InstanceStatusStopped); err != nil {
-		return fmt.Errorf("failed to wait for instance status: %s", logHttp500(err))
-	}
-	return nil
+	return d.StopInstance(false)
 }
 
+// Remove removes the machine and all resources created by the driver. Removal of every resource
+// is attempted, so errors of all failed removals are returned together
 func (d *Driver) Remove() error {
-	var errs error
+	var errs *multierror.Error
 	if err := d.Authenticate(); err != nil {
 		return err
 	}
-	if err := d.deleteInstance(); err != nil {
+	if d.InstanceID != "" {
+		// attached existing volume has to be detached, so it's not deleted with the instance
+		if err := d.detachVolume(); err != nil {
+			errs = multierror.Append(errs, err)
+		} else if err := d.deleteInstance(); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+	if err := d.deleteServerGroup(); err != nil {
 		errs = multierror.Append(errs, err)
 	}
-	if d.KeyPairName.DriverManaged {
-		if err := d.client.DeleteKeyPair(d.KeyPairName.Value); err != nil {
-			errs = multierror.Append(errs, fmt.Errorf("failed to delete key pair: %s", logHttp500(err)))
-		}
+	if err := d.deleteKeyPair(); err != nil {
+		errs = multierror.Append(errs, err)
 	}
-	if d.ElasticIP.DriverManaged && d.ElasticIP.Value != "" {
-		if err := d.client.DeleteFloatingIP(d.ElasticIP.Value); err != nil {
-			errs = multierror.Append(errs, fmt.Errorf("failed to delete floating IP: %s", logHttp500(err)))
+	if !d.skipEIPCreation && d.ElasticIP.DriverManaged && d.ElasticIP.Value != "" {
+		if err := d.leaveSharedBandwidth(); err != nil {
+			errs = multierror.Append(errs, err)
+		} else if err := d.DeleteFloatingIP(d.ElasticIP.Value, true); err != nil {
+			errs = multierror.Append(errs, err)
+		} else if err := d.waitForEIPDeleted(d.ElasticIP.Value); err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("failed to wait for floating IP deletion: %s", logHttp500(err)))
 		}
 	}
+	if err := d.deleteNatGateway(); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+	if err := d.removeRoutes(); err != nil {
+		errs = multierror.Append(errs, err)
+	} else if err := d.deletePeering(); err != nil {
+		errs = multierror.Append(errs, err)
+	}
 	if err := d.deleteSubnet(); err != nil {
 		errs = multierror.Append(errs, err)
 	}
@@ -231,18 +542,24 @@ func (d *Driver) Remove() error {
 	if err := d.deleteVPC(); err != nil {
 		errs = multierror.Append(errs, err)
 	}
-	return errs
+	return errs.ErrorOrNil()
 }
 
+// Restart reboots the instance, hard reboot is used if `ForceRestart` is set
 func (d *Driver) Restart() error {
-	if err := d.Stop(); err != nil {
-		return err
-	}
-	return d.Start()
+	return d.RestartInstance(d.ForceRestart)
 }
 
+// Kill deletes the instance without graceful stop, so hung instances are removed quickly
 func (d *Driver) Kill() error {
-	return d.Stop()
+	if d.InstanceID == "" {
+		return nil
+	}
+	if err := d.DeleteInstanceForce(d.InstanceID); err != nil {
+		return err
+	}
+	d.InstanceID = ""
+	return nil
 }
 
 // NewDriver create new driver instance
@@ -262,14 +579,29 @@ func (d *Driver) DriverName() string {
 	return driverName
 }
 
+// GetSSHHostname returns address used for SSH, it's local tunnel address if bastion host is used
 func (d *Driver) GetSSHHostname() (string, error) {
+	if d.BastionHost != "" {
+		if _, err := d.startBastionTunnel(); err != nil {
+			return "", err
+		}
+		return localhost, nil
+	}
 	return d.GetIP()
 }
 
+// GetSSHPort returns port used for SSH, it's local tunnel port if bastion host is used
 func (d *Driver) GetSSHPort() (int, error) {
 	if d.SSHPort == 0 {
 		d.SSHPort = defaultSSHPort
 	}
+	if d.BastionHost != "" {
+		tunnel, err := d.startBastionTunnel()
+		if err != nil {
+			return 0, err
+		}
+		return tunnel.port(), nil
+	}
 	return d.SSHPort, nil
 }
 
@@ -281,11 +613,34 @@ func (d *Driver) GetSSHUsername() string {
 }
 
 func (d *Driver) GetIP() (string, error) {
+	if d.UseIPv6SSH && d.IPv6Address != "" {
+		d.IPAddress = d.IPv6Address
+		return d.BaseDriver.GetIP()
+	}
+	if d.ElasticIP.Value == "" && d.InstanceID != "" {
+		if d.skipEIPCreation {
+			if err := d.useLocalIP(); err != nil {
+				return "", err
+			}
+		} else {
+			// elastic IP may be bound outside of the driver
+			address, err := d.GetFloatingIP(d.InstanceID)
+			if err != nil {
+				return "", err
+			}
+			d.ElasticIP = managedSting{Value: address}
+		}
+	}
 	d.IPAddress = d.ElasticIP.Value
 	return d.BaseDriver.GetIP()
 }
 
+// GetURL returns docker daemon URL using the machine IP, `drivers.ErrHostIsNotRunning`
+// is returned if the instance is not running
 func (d *Driver) GetURL() (string, error) {
+	if err := drivers.MustBeRunning(d); err != nil {
+		return "", err
+	}
 	ip, err := d.GetIP()
 	if err != nil || ip == "" {
 		return "", err
@@ -293,26 +648,82 @@ func (d *Driver) GetURL() (string, error) {
 	return fmt.Sprintf("tcp://%s", net.JoinHostPort(ip, strconv.Itoa(dockerPort))), nil
 }
 
+// instanceStates maps instance statuses to machine states, unknown statuses are mapped to `state.None`.
+// Instance is still running during reboot, resize and migration
+var instanceStates = map[string]state.State{
+	services.InstanceStatusRunning: state.Running,
+	"REBOOT":                       state.Running,
+	"HARD_REBOOT":                  state.Running,
+	"PASSWORD":                     state.Running,
+	"MIGRATING":                    state.Running,
+	"RESIZE":                       state.Running,
+	instanceStatusVerifyResize:     state.Running,
+	"REVERT_RESIZE":                state.Running,
+	"RESCUE":                       state.Running,
+	"BUILD":                        state.Starting,
+	"REBUILD":                      state.Starting,
+	services.InstanceStatusStopped: state.Stopped,
+	"SHELVED":                      state.Stopped,
+	"SHELVED_OFFLOADED":            state.Stopped,
+	"PAUSED":                       state.Paused,
+	"SUSPENDED":                    state.Saved,
+	instanceStatusError:            state.Error,
+	"DELETED":                      state.None,
+	"SOFT_DELETED":                 state.None,
+}
+
+// taskStates maps instance task states to machine states during transitions
+var taskStates = map[string]state.State{
+	"scheduling":           state.Starting,
+	"block_device_mapping": state.Starting,
+	"networking":           state.Starting,
+	"spawning":             state.Starting,
+	"powering-on":          state.Starting,
+	"rebooting":            state.Starting,
+	"reboot_pending":       state.Starting,
+	"reboot_started":       state.Starting,
+	"rebooting_hard":       state.Starting,
+	"reboot_pending_hard":  state.Starting,
+	"reboot_started_hard":  state.Starting,
+	"rebuilding":           state.Starting,
+	"unpausing":            state.Starting,
+	"resuming":             state.Starting,
+	"powering-off":         state.Stopping,
+	"pausing":              state.Stopping,
+	"suspending":           state.Stopping,
+	"shelving":             state.Stopping,
+	"deleting":             state.Stopping,
+}
+
+// instanceTaskState returns machine state matching the instance status and task state,
+// task state takes precedence as the status is not changed until the task is finished
+func instanceTaskState(status, taskState string) state.State {
+	if st, ok := taskStates[taskState]; ok {
+		return st
+	}
+	return instanceState(status)
+}
+
+// instanceState returns machine state matching instance status
+func instanceState(status string) state.State {
+	if st, ok := instanceStates[status]; ok {
+		return st
+	}
+	return state.None
+}
+
+// GetState returns machine state mapped from instance status and task state,
+// `state.None` without error is returned if instance doesn't exist
 func (d *Driver) GetState() (state.State, error) {
 	if err := d.initComputeV2(); err != nil {
 		return state.None, err
 	}
-	instance, err := d.client.GetInstanceStatus(d.InstanceID)
-	if err != nil {
-		return state.None, fmt.Errorf("failed to get instance state: %s", logHttp500(err))
-	}
-	switch instance.Status {
-	case services.InstanceStatusRunning:
-		return state.Running, nil
-	case "PAUSED":
-		return state.Paused, nil
-	case services.InstanceStatusStopped:
-		return state.Stopped, nil
-	case "BUILDING":
-		return state.Starting, nil
-	case "ERROR":
-		return state.Error, nil
-	default:
+	instance, err := d.GetInstanceStatus(d.InstanceID)
+	if errors.Is(err, ErrInstanceNotFound) {
 		return state.None, nil
 	}
+	if err != nil {
+		return state.None, fmt.Errorf("failed to get instance state: %w", logHttp500(err))
+	}
+	return instanceTaskState(instance.Status, instance.TaskState), nil
 }
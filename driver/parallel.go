@@ -0,0 +1,44 @@
+package opentelekomcloud
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// creationStep is a named step of resource creation which doesn't depend on other steps
+type creationStep struct {
+	name string
+	run  func() error
+}
+
+// runParallel runs independent creation steps concurrently and waits for all of them to finish,
+// so every created resource is already recorded in the driver when rollback starts.
+// Step name prefixes its log messages and errors
+func runParallel(logger Logger, steps []creationStep) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(steps))
+	for i, step := range steps {
+		wg.Add(1)
+		go func(i int, step creationStep) {
+			defer wg.Done()
+			logger.Debugf("[%s] started", step.name)
+			if err := step.run(); err != nil {
+				logger.Debugf("[%s] failed: %s", step.name, err)
+				errs[i] = fmt.Errorf("[%s] %s", step.name, err)
+				return
+			}
+			logger.Debugf("[%s] finished", step.name)
+		}(i, step)
+	}
+	wg.Wait()
+
+	var result *multierror.Error
+	for _, err := range errs {
+		if err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+	return result.ErrorOrNil()
+}
@@ -0,0 +1,104 @@
+package opentelekomcloud
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/peerings"
+)
+
+const (
+	peeringStatusActive  = "ACTIVE"
+	peeringStatusPending = "PENDING_ACCEPTANCE"
+)
+
+// parsePeerRoutes parses comma-separated list of CIDRs routed via VPC peering
+func parsePeerRoutes(cidrs string) ([]string, error) {
+	var result []string
+	for _, cidr := range strings.Split(cidrs, ",") {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return nil, fmt.Errorf("invalid peer route CIDR `%s`: %s", cidr, err)
+		}
+		result = append(result, cidr)
+	}
+	return result, nil
+}
+
+// CreatePeering creates VPC peering connection between local VPC and peer VPC.
+// Peer project ID is required only if peer VPC belongs to another project
+func (d *Driver) CreatePeering(localVpcID, peerVpcID, peerProjectID string) (string, error) {
+	peering, err := peerings.Create(d.network, peerings.CreateOpts{
+		Name:           fmt.Sprintf("%s-peering", d.MachineName),
+		RequestVpcInfo: peerings.VpcInfo{VpcId: localVpcID},
+		AcceptVpcInfo:  peerings.VpcInfo{VpcId: peerVpcID, TenantId: peerProjectID},
+	}).Extract()
+	if err != nil {
+		return "", fmt.Errorf("failed to create VPC peering: %s", logHttp500(err))
+	}
+	return peering.ID, nil
+}
+
+// AcceptPeering accepts VPC peering connection request, it has to be called by the peer project
+func (d *Driver) AcceptPeering(peeringID string) error {
+	if err := peerings.Accept(d.network, peeringID).Err; err != nil {
+		return fmt.Errorf("failed to accept VPC peering: %s", logHttp500(err))
+	}
+	return nil
+}
+
+// DeletePeering deletes VPC peering connection and waits for the deletion to finish
+func (d *Driver) DeletePeering(peeringID string) error {
+	if err := peerings.Delete(d.network, peeringID).ExtractErr(); err != nil {
+		return fmt.Errorf("failed to delete VPC peering: %s", logHttp500(err))
+	}
+	if err := d.WaitForPeeringDeleted(peeringID); err != nil {
+		return fmt.Errorf("failed to wait for VPC peering deletion: %s", logHttp500(err))
+	}
+	return nil
+}
+
+// crossProjectPeering reports if the peer VPC belongs to another project
+func (d *Driver) crossProjectPeering() bool {
+	return d.PeerProjectID != "" && d.PeerProjectID != d.network.ProviderClient.ProjectID
+}
+
+// createPeering peers the machine VPC with configured peer VPC and adds routes to the peer networks.
+// Peering with VPC of another project has to be accepted by that project before routes can be added
+func (d *Driver) createPeering() error {
+	if d.PeerVpcID == "" || d.PeeringID != "" {
+		return nil
+	}
+	peeringID, err := d.CreatePeering(d.VpcID.Value, d.PeerVpcID, d.PeerProjectID)
+	if err != nil {
+		return err
+	}
+	d.PeeringID = peeringID
+	if d.crossProjectPeering() {
+		if err := d.WaitForPeeringStatus(peeringID, peeringStatusPending); err != nil {
+			return fmt.Errorf("failed to wait for VPC peering creation: %s", logHttp500(err))
+		}
+		d.logger().Infof("VPC peering `%s` has to be accepted by project `%s`, routes via the peering are not added",
+			peeringID, d.PeerProjectID)
+		return nil
+	}
+	if err := d.WaitForPeeringStatus(peeringID, peeringStatusActive); err != nil {
+		return fmt.Errorf("failed to wait for VPC peering to be active: %s", logHttp500(err))
+	}
+	for _, cidr := range d.PeerRoutes {
+		d.Routes = append(d.Routes, vpcRoute{Destination: cidr, NextHop: peeringID})
+	}
+	return d.addRoutes()
+}
+
+// deletePeering removes VPC peering created by the driver, routes via the peering have to be removed before
+func (d *Driver) deletePeering() error {
+	if d.PeeringID == "" {
+		return nil
+	}
+	if err := d.DeletePeering(d.PeeringID); err != nil {
+		return err
+	}
+	d.PeeringID = ""
+	return nil
+}
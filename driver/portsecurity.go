@@ -0,0 +1,121 @@
+package opentelekomcloud
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/extensions/secgroups"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/extensions/portsecurity"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/ports"
+)
+
+// UpdatePort updates the port with given options
+func (d *Driver) UpdatePort(portID string, opts ports.UpdateOptsBuilder) (*ports.Port, error) {
+	port, err := ports.Update(d.network, portID, opts).Extract()
+	if err != nil {
+		return nil, fmt.Errorf("failed to update port %s: %s", portID, logHttp500(err))
+	}
+	return port, nil
+}
+
+// instanceSecurityGroups returns IDs of all security groups of the instance
+func (d *Driver) instanceSecurityGroups() []string {
+	groups := append([]string{}, d.SecurityGroupIDs...)
+	if d.ManagedSecurityGroupID != "" {
+		groups = append(groups, d.ManagedSecurityGroupID)
+	}
+	return groups
+}
+
+// SetPortSecurity enables or disables port security (source/destination check) of the primary instance port.
+// Port with disabled port security can't have security groups, so they are removed from the port
+// and restored when port security is enabled again
+func (d *Driver) SetPortSecurity(enabled bool) error {
+	port, err := d.primaryPort()
+	if err != nil {
+		return err
+	}
+	groups := []string{}
+	if enabled {
+		groups = d.instanceSecurityGroups()
+	}
+	opts := portsecurity.PortUpdateOptsExt{
+		UpdateOptsBuilder:   ports.UpdateOpts{SecurityGroups: &groups},
+		PortSecurityEnabled: &enabled,
+	}
+	if _, err := d.UpdatePort(port.ID, opts); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetInstanceSecurityGroups returns security groups currently applied to the instance
+func (d *Driver) GetInstanceSecurityGroups(instanceID string) ([]secgroups.SecurityGroup, error) {
+	if err := d.initComputeV2(); err != nil {
+		return nil, err
+	}
+	pages, err := secgroups.ListByServer(d.compute, instanceID).AllPages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list security groups of instance %s: %s", instanceID, logHttp500(err))
+	}
+	groups, err := secgroups.ExtractSecurityGroups(pages)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract security groups: %s", err)
+	}
+	return groups, nil
+}
+
+// missingSecurityGroups returns IDs of expected groups which are not applied
+func missingSecurityGroups(expected []string, applied []secgroups.SecurityGroup) []string {
+	appliedSet := make(map[string]bool, len(applied))
+	for _, group := range applied {
+		appliedSet[group.ID] = true
+	}
+	var missing []string
+	for _, id := range expected {
+		if !appliedSet[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
+
+// tcpPortAllowed reports if any of the groups allows ingress TCP traffic to the port
+func tcpPortAllowed(groups []secgroups.SecurityGroup, port int) bool {
+	for _, group := range groups {
+		for _, rule := range group.Rules {
+			if strings.EqualFold(rule.IPProtocol, "tcp") && rule.FromPort <= port && port <= rule.ToPort {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// diagnoseSSHTimeout logs if SSH port is not reachable because security groups are not applied
+// to the instance yet or because the instance is still booting
+func (d *Driver) diagnoseSSHTimeout() {
+	if d.DisablePortSecurity {
+		d.logger().Warnf("SSH port is not reachable after %d seconds, port security is disabled, "+
+			"so the instance is probably still booting", d.SSHGracePeriod)
+		return
+	}
+	groups, err := d.GetInstanceSecurityGroups(d.InstanceID)
+	if err != nil {
+		d.logger().Warnf("SSH port is not reachable after %d seconds, failed to check security groups: %s",
+			d.SSHGracePeriod, err)
+		return
+	}
+	if missing := missingSecurityGroups(d.instanceSecurityGroups(), groups); len(missing) > 0 {
+		d.logger().Warnf("SSH port is not reachable after %d seconds, security groups [%s] are not applied "+
+			"to the instance yet", d.SSHGracePeriod, strings.Join(missing, ", "))
+		return
+	}
+	if !tcpPortAllowed(groups, d.SSHPort) {
+		d.logger().Warnf("SSH port is not reachable after %d seconds, no security group of the instance "+
+			"allows TCP port %d", d.SSHGracePeriod, d.SSHPort)
+		return
+	}
+	d.logger().Warnf("SSH port is not reachable after %d seconds while security groups are applied, "+
+		"the instance is probably still booting", d.SSHGracePeriod)
+}
@@ -0,0 +1,179 @@
+package opentelekomcloud
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/blockstorage/extensions/quotasets"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/extensions/limits"
+)
+
+// Quota contains limit and usage of single resource, negative limit means no limit
+type Quota struct {
+	Limit int
+	Used  int
+}
+
+// Remaining returns amount of resource which can still be allocated, -1 is returned for unlimited quota
+func (q Quota) Remaining() int {
+	if q.Limit < 0 {
+		return -1
+	}
+	if q.Used >= q.Limit {
+		return 0
+	}
+	return q.Limit - q.Used
+}
+
+// Quotas contains project quotas of resources created by the driver
+type Quotas struct {
+	Instances  Quota
+	VCPUs      Quota
+	RAM        Quota // in MB
+	Volumes    Quota
+	ElasticIPs Quota
+}
+
+// quotaRequest contains amount of resources required for machine creation
+type quotaRequest struct {
+	Instances  int
+	VCPUs      int
+	RAM        int
+	Volumes    int
+	ElasticIPs int
+}
+
+const publicIPQuotaType = "publicIp"
+
+func (d *Driver) initVolume() error {
+	if d.volume != nil {
+		return nil
+	}
+	if err := d.Authenticate(); err != nil {
+		return fmt.Errorf("failed to authenticate: %s", logHttp500(err))
+	}
+	volume, err := d.client.NewServiceClient("volume")
+	if err != nil {
+		return fmt.Errorf("failed to initialize Block Storage service: %s", logHttp500(err))
+	}
+	d.volume = volume
+	return nil
+}
+
+// GetQuotas returns project quotas and usage of instances, vCPUs, RAM, volumes and elastic IPs
+func (d *Driver) GetQuotas() (*Quotas, error) {
+	if err := d.initComputeV2(); err != nil {
+		return nil, err
+	}
+	if err := d.initVolume(); err != nil {
+		return nil, err
+	}
+	if err := d.initNetwork(); err != nil {
+		return nil, err
+	}
+
+	computeLimits, err := limits.Get(d.compute, nil).Extract()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get compute limits: %s", logHttp500(err))
+	}
+	abs := computeLimits.Absolute
+	result := &Quotas{
+		Instances: Quota{Limit: abs.MaxTotalInstances, Used: abs.TotalInstancesUsed},
+		VCPUs:     Quota{Limit: abs.MaxTotalCores, Used: abs.TotalCoresUsed},
+		RAM:       Quota{Limit: abs.MaxTotalRAMSize, Used: abs.TotalRAMUsed},
+	}
+
+	volumeUsage, err := quotasets.GetUsage(d.volume, d.volume.ProviderClient.ProjectID).Extract()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get volume quotas: %s", logHttp500(err))
+	}
+	result.Volumes = Quota{
+		Limit: volumeUsage.Volumes.Limit,
+		Used:  volumeUsage.Volumes.InUse + volumeUsage.Volumes.Reserved,
+	}
+
+	var vpcQuotas struct {
+		Quotas struct {
+			Resources []struct {
+				Type  string `json:"type"`
+				Used  int    `json:"used"`
+				Quota int    `json:"quota"`
+			} `json:"resources"`
+		} `json:"quotas"`
+	}
+	quotaURL := d.vpc.ServiceURL(d.vpc.ProjectID, "quotas") + "?type=" + publicIPQuotaType
+	if _, err := d.vpc.Get(quotaURL, &vpcQuotas, nil); err != nil {
+		return nil, fmt.Errorf("failed to get elastic IP quotas: %s", logHttp500(err))
+	}
+	result.ElasticIPs = Quota{Limit: -1}
+	for _, res := range vpcQuotas.Quotas.Resources {
+		if res.Type == publicIPQuotaType {
+			result.ElasticIPs = Quota{Limit: res.Quota, Used: res.Used}
+		}
+	}
+	return result, nil
+}
+
+// quotaShortages returns descriptions of quotas which are not enough for the request
+func quotaShortages(quotas *Quotas, request quotaRequest) []string {
+	var shortages []string
+	check := func(name string, quota Quota, requested int) {
+		remaining := quota.Remaining()
+		if requested == 0 || remaining < 0 || remaining >= requested {
+			return
+		}
+		shortages = append(shortages, fmt.Sprintf("%s (requested %d, available %d of %d)",
+			name, requested, remaining, quota.Limit))
+	}
+	check("instances", quotas.Instances, request.Instances)
+	check("vCPUs", quotas.VCPUs, request.VCPUs)
+	check("RAM (MB)", quotas.RAM, request.RAM)
+	check("volumes", quotas.Volumes, request.Volumes)
+	check("elastic IPs", quotas.ElasticIPs, request.ElasticIPs)
+	return shortages
+}
+
+// findFlavorDetails returns flavor matching configured flavor ID or name
+func (d *Driver) findFlavorDetails() (*Flavor, error) {
+	if d.FlavorID != "" {
+		return d.FindFlavorDetails(d.FlavorID)
+	}
+	return d.FindFlavorDetails(d.FlavorName)
+}
+
+// quotaRequest returns amount of resources required for the machine
+func (d *Driver) quotaRequest() (quotaRequest, error) {
+	flavor, err := d.findFlavorDetails()
+	if err != nil {
+		return quotaRequest{}, err
+	}
+	request := quotaRequest{
+		Instances: 1,
+		VCPUs:     flavor.VCPUs,
+		RAM:       flavor.RAM,
+		Volumes:   1 + len(d.DataVolumes),
+	}
+	if !d.skipEIPCreation && d.ElasticIP.Value == "" {
+		request.ElasticIPs++
+	}
+	if d.UseNatGateway {
+		request.ElasticIPs++
+	}
+	return request, nil
+}
+
+// checkQuotas checks that project quotas are enough for machine creation
+func (d *Driver) checkQuotas() error {
+	request, err := d.quotaRequest()
+	if err != nil {
+		return err
+	}
+	quotas, err := d.GetQuotas()
+	if err != nil {
+		return err
+	}
+	if shortages := quotaShortages(quotas, request); len(shortages) > 0 {
+		return fmt.Errorf("project quotas are not enough to create the machine: %s", strings.Join(shortages, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package opentelekomcloud
+
+import (
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultAPIRetries    = 3
+	defaultAPIRetryDelay = 1 // in seconds
+)
+
+// retryTransport retries API requests failed with transient errors using exponential backoff with jitter
+type retryTransport struct {
+	base       http.RoundTripper
+	maxRetries int
+	baseDelay  time.Duration
+	logger     Logger
+}
+
+// isIdempotent reports if request can be safely repeated after network error or server error
+func isIdempotent(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// isTransientStatus reports if response status means that request can be retried.
+// `429` and `503` mean that request wasn't processed, so any request can be repeated,
+// other server errors are retried for idempotent requests only
+func isTransientStatus(method string, status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return isIdempotent(method)
+	}
+	return false
+}
+
+// backoff returns delay before given retry attempt (starting from 0)
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	delay := t.baseDelay << uint(attempt)
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// RoundTrip sends the request, retries are sent using copies of the request,
+// so the original request is never modified as required by `http.RoundTripper`
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	attemptReq := req
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(attemptReq)
+		retry := false
+		switch {
+		case err != nil:
+			retry = isIdempotent(req.Method)
+		case isTransientStatus(req.Method, resp.StatusCode):
+			retry = true
+		}
+		if !retry || attempt >= t.maxRetries {
+			return resp, err
+		}
+		nextReq := req.Clone(req.Context())
+		if req.Body != nil {
+			if req.GetBody == nil {
+				return resp, err
+			}
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return resp, err
+			}
+			nextReq.Body = body
+		}
+		if resp != nil {
+			_ = resp.Body.Close()
+			orDefaultLogger(t.logger).Debugf("%s %s failed with status %d, retrying", req.Method, req.URL, resp.StatusCode)
+		} else {
+			orDefaultLogger(t.logger).Debugf("%s %s failed: %s, retrying", req.Method, req.URL, err)
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(t.backoff(attempt)):
+		}
+		attemptReq = nextReq
+	}
+}
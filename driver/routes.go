@@ -0,0 +1,243 @@
+package opentelekomcloud
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v1/vpcs"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/routes"
+)
+
+const (
+	routeTypePeering       = "peering"
+	maxRouteUpdateAttempts = 5
+)
+
+// vpcRoute is a static route of the VPC, `DriverManaged` is set for routes added by the driver
+type vpcRoute struct {
+	Destination   string `json:"destination"`
+	NextHop       string `json:"nexthop"`
+	DriverManaged bool   `json:"managed"`
+}
+
+// parseRoutes parses comma-separated list of `cidr=nexthop` route definitions
+func parseRoutes(routes string) ([]vpcRoute, error) {
+	var result []vpcRoute
+	for _, def := range strings.Split(routes, ",") {
+		parts := strings.SplitN(strings.TrimSpace(def), "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid route `%s`, expected `cidr=nexthop`", def)
+		}
+		if _, _, err := net.ParseCIDR(parts[0]); err != nil {
+			return nil, fmt.Errorf("invalid route destination `%s`: %s", parts[0], err)
+		}
+		if ip := net.ParseIP(parts[1]); ip == nil || ip.To4() == nil {
+			return nil, fmt.Errorf("invalid route next hop `%s`, IPv4 address expected", parts[1])
+		}
+		result = append(result, vpcRoute{Destination: parts[0], NextHop: parts[1]})
+	}
+	return result, nil
+}
+
+// getVPCRoutes returns static routes of the VPC
+func (d *Driver) getVPCRoutes(vpcID string) ([]vpcs.Route, error) {
+	vpc, err := vpcs.Get(d.vpc, vpcID).Extract()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VPC details: %s", logHttp500(err))
+	}
+	return vpc.Routes, nil
+}
+
+// setVPCRoutes replaces static routes of the VPC
+func (d *Driver) setVPCRoutes(vpcID string, routes []vpcs.Route) error {
+	if routes == nil {
+		routes = []vpcs.Route{}
+	}
+	body := map[string]interface{}{
+		"vpc": map[string]interface{}{"routes": routes},
+	}
+	_, err := d.vpc.Put(d.vpc.ServiceURL(d.vpc.ProjectID, "vpcs", vpcID), body, nil, &golangsdk.RequestOpts{
+		OkCodes: []int{200},
+	})
+	return err
+}
+
+// hasVPCRoute reports if the routes contain the route
+func hasVPCRoute(routes []vpcs.Route, route vpcs.Route) bool {
+	for _, r := range routes {
+		if r.DestinationCIDR == route.DestinationCIDR && r.NextHop == route.NextHop {
+			return true
+		}
+	}
+	return false
+}
+
+// updateVPCRoute adds or removes single static route of the VPC, `false` is returned if nothing is changed.
+// VPC routes can only be replaced all at once, so the routes are re-read right before every update to keep
+// routes added by others. The update is repeated if it failed with conflict or if the route was
+// overwritten by concurrent update
+func (d *Driver) updateVPCRoute(vpcID string, route vpcs.Route, add bool) (bool, error) {
+	changed := false
+	for attempt := 1; ; attempt++ {
+		current, err := d.getVPCRoutes(vpcID)
+		if err != nil {
+			return false, err
+		}
+		var updated []vpcs.Route
+		for _, r := range current {
+			if r.DestinationCIDR != route.DestinationCIDR {
+				updated = append(updated, r)
+				continue
+			}
+			if r.NextHop != route.NextHop {
+				if add {
+					return false, fmt.Errorf("route to `%s` via `%s` already exists in VPC `%s`",
+						route.DestinationCIDR, r.NextHop, vpcID)
+				}
+				updated = append(updated, r)
+			}
+		}
+		if add {
+			updated = append(updated, route)
+		}
+		if hasVPCRoute(current, route) == add {
+			// the route is already in the desired state, probably after previous attempt
+			return changed, nil
+		}
+
+		err = d.setVPCRoutes(vpcID, updated)
+		if err == nil {
+			changed = true
+			current, err = d.getVPCRoutes(vpcID)
+			if err != nil {
+				return false, err
+			}
+			if hasVPCRoute(current, route) == add {
+				return true, nil
+			}
+			err = fmt.Errorf("VPC routes are changed concurrently")
+		} else if _, ok := err.(golangsdk.ErrDefault409); !ok {
+			return false, fmt.Errorf("failed to update VPC routes: %s", logHttp500(err))
+		}
+		if attempt >= maxRouteUpdateAttempts {
+			return false, fmt.Errorf("failed to update VPC routes: %s", logHttp500(err))
+		}
+		d.logger().Debugf("Failed to update VPC routes (attempt %d): %s, retrying", attempt, err)
+	}
+}
+
+// findPeeringRoute returns route to the destination via VPC peering connection, nil is returned if there is no such route
+func (d *Driver) findPeeringRoute(vpcID, destinationCIDR string) (*routes.Route, error) {
+	pages, err := routes.List(d.network, routes.ListOpts{
+		Type:        routeTypePeering,
+		VPC_ID:      vpcID,
+		Destination: destinationCIDR,
+	}).AllPages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list VPC routes: %s", logHttp500(err))
+	}
+	routeList, err := routes.ExtractRoutes(pages)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract VPC routes: %s", logHttp500(err))
+	}
+	for _, route := range routeList {
+		if route.Destination == destinationCIDR {
+			return &route, nil
+		}
+	}
+	return nil, nil
+}
+
+// addPeeringRoute adds route to the destination via VPC peering connection
+func (d *Driver) addPeeringRoute(vpcID, destinationCIDR, peeringID string) (bool, error) {
+	existing, err := d.findPeeringRoute(vpcID, destinationCIDR)
+	if err != nil {
+		return false, err
+	}
+	if existing != nil {
+		if existing.NextHop == peeringID {
+			return false, nil
+		}
+		return false, fmt.Errorf("route to `%s` via `%s` already exists in VPC `%s`",
+			destinationCIDR, existing.NextHop, vpcID)
+	}
+	if err := routes.Create(d.network, routes.CreateOpts{
+		Type:        routeTypePeering,
+		NextHop:     peeringID,
+		Destination: destinationCIDR,
+		VPC_ID:      vpcID,
+	}).Err; err != nil {
+		return false, fmt.Errorf("failed to create VPC route: %s", logHttp500(err))
+	}
+	return true, nil
+}
+
+// removePeeringRoute removes route to the destination via VPC peering connection
+func (d *Driver) removePeeringRoute(vpcID, destinationCIDR, peeringID string) error {
+	existing, err := d.findPeeringRoute(vpcID, destinationCIDR)
+	if err != nil {
+		return err
+	}
+	if existing == nil || existing.NextHop != peeringID {
+		return nil
+	}
+	if err := routes.Delete(d.network, existing.RouteID).ExtractErr(); err != nil {
+		return fmt.Errorf("failed to delete VPC route: %s", logHttp500(err))
+	}
+	return nil
+}
+
+// AddRoute adds static route to the VPC, `false` is returned if the same route already exists.
+// Next hop is either an IP address in the VPC or ID of VPC peering connection
+func (d *Driver) AddRoute(vpcID, destinationCIDR, nextHop string) (bool, error) {
+	if net.ParseIP(nextHop) == nil {
+		return d.addPeeringRoute(vpcID, destinationCIDR, nextHop)
+	}
+	return d.updateVPCRoute(vpcID, vpcs.Route{DestinationCIDR: destinationCIDR, NextHop: nextHop}, true)
+}
+
+// RemoveRoute removes static route from the VPC, missing route is ignored
+func (d *Driver) RemoveRoute(vpcID, destinationCIDR, nextHop string) error {
+	if net.ParseIP(nextHop) == nil {
+		return d.removePeeringRoute(vpcID, destinationCIDR, nextHop)
+	}
+	_, err := d.updateVPCRoute(vpcID, vpcs.Route{DestinationCIDR: destinationCIDR, NextHop: nextHop}, false)
+	return err
+}
+
+// addRoutes adds configured routes to the machine VPC
+func (d *Driver) addRoutes() error {
+	for i, route := range d.Routes {
+		if route.DriverManaged {
+			continue
+		}
+		added, err := d.AddRoute(d.VpcID.Value, route.Destination, route.NextHop)
+		if err != nil {
+			return err
+		}
+		d.Routes[i].DriverManaged = added
+	}
+	return nil
+}
+
+// removeRoutes removes routes added by the driver, removal of every route is attempted
+func (d *Driver) removeRoutes() error {
+	if d.VpcID.Value == "" {
+		return nil
+	}
+	var errs *multierror.Error
+	for i, route := range d.Routes {
+		if !route.DriverManaged {
+			continue
+		}
+		if err := d.RemoveRoute(d.VpcID.Value, route.Destination, route.NextHop); err != nil {
+			errs = multierror.Append(errs, err)
+			continue
+		}
+		d.Routes[i].DriverManaged = false
+	}
+	return errs.ErrorOrNil()
+}
@@ -0,0 +1,132 @@
+package opentelekomcloud
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/extensions/security/rules"
+)
+
+// icmpAny matches any ICMP type or code
+const icmpAny = -1
+
+// secGroupRule is security group rule definition,
+// for ICMP rules `From` and `To` are ICMP type and code respectively
+type secGroupRule struct {
+	Protocol rules.RuleProtocol
+	From     int
+	To       int
+	CIDR     string
+}
+
+func etherType(cidr string) rules.RuleEtherType {
+	if ip, _, err := net.ParseCIDR(cidr); err == nil && ip.To4() == nil {
+		return rules.EtherType6
+	}
+	return rules.EtherType4
+}
+
+// setEgressRules replaces default allow-all egress rules of security group with configured ones
+func (d *Driver) setEgressRules(secGroupID string) error {
+	pages, err := rules.List(d.network, rules.ListOpts{
+		SecGroupID: secGroupID,
+		Direction:  string(rules.DirEgress),
+	}).AllPages()
+	if err != nil {
+		return fmt.Errorf("failed to list egress rules: %s", logHttp500(err))
+	}
+	existing, err := rules.ExtractRules(pages)
+	if err != nil {
+		return fmt.Errorf("failed to extract egress rules: %s", err)
+	}
+	for _, rule := range existing {
+		if err := rules.Delete(d.network, rule.ID).ExtractErr(); err != nil {
+			return fmt.Errorf("failed to delete default egress rule: %s", logHttp500(err))
+		}
+	}
+	for _, rule := range d.EgressRules {
+		if err := d.createRule(secGroupID, rules.DirEgress, rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addIngressRules opens SSH, docker and additional ports of security group for each of the source CIDRs
+func (d *Driver) addIngressRules(secGroupID string) error {
+	var ports []secGroupRule
+	if len(d.SourceCIDRs) > 0 {
+		ports = append(ports,
+			secGroupRule{From: d.SSHPort, To: d.SSHPort},
+			secGroupRule{From: dockerPort, To: dockerPort},
+		)
+	}
+	ports = append(ports, d.OpenPorts...)
+	if d.AllowPing {
+		ports = append(ports, secGroupRule{Protocol: rules.ProtocolICMP, From: icmpAny, To: icmpAny})
+	}
+
+	cidrs := d.SourceCIDRs
+	if len(cidrs) == 0 {
+		cidrs = []string{cidrAll}
+	}
+	for _, cidr := range cidrs {
+		for _, rule := range ports {
+			rule.CIDR = cidr
+			if err := d.createRule(secGroupID, rules.DirIngress, rule); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// createRule creates security group rule if the same rule doesn't exist yet
+func (d *Driver) createRule(secGroupID string, direction rules.RuleDirection, rule secGroupRule) error {
+	if rule.Protocol == "" {
+		rule.Protocol = rules.ProtocolTCP
+	}
+	if rule.To == 0 {
+		rule.To = rule.From
+	}
+	if rule.Protocol == rules.ProtocolICMP {
+		// omitted ICMP type and code match any
+		if rule.From == icmpAny {
+			rule.From = 0
+		}
+		if rule.To == icmpAny {
+			rule.To = 0
+		}
+	}
+	pages, err := rules.List(d.network, rules.ListOpts{
+		SecGroupID:     secGroupID,
+		Direction:      string(direction),
+		Protocol:       string(rule.Protocol),
+		PortRangeMin:   rule.From,
+		PortRangeMax:   rule.To,
+		RemoteIPPrefix: rule.CIDR,
+	}).AllPages()
+	if err != nil {
+		return fmt.Errorf("failed to list %s rules: %s", direction, logHttp500(err))
+	}
+	existing, err := rules.ExtractRules(pages)
+	if err != nil {
+		return fmt.Errorf("failed to extract %s rules: %s", direction, err)
+	}
+	if len(existing) > 0 {
+		return nil
+	}
+	opts := rules.CreateOpts{
+		Direction:      direction,
+		EtherType:      etherType(rule.CIDR),
+		SecGroupID:     secGroupID,
+		PortRangeMin:   rule.From,
+		PortRangeMax:   rule.To,
+		Protocol:       rule.Protocol,
+		RemoteIPPrefix: rule.CIDR,
+	}
+	if err := rules.Create(d.network, opts).Err; err != nil {
+		return fmt.Errorf("failed to create %s rule: %s", direction, logHttp500(err))
+	}
+	return nil
+}
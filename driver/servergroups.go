@@ -0,0 +1,62 @@
+package opentelekomcloud
+
+import (
+	"fmt"
+
+	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/extensions/servergroups"
+)
+
+const serverGroupPolicyAntiAffinity = "anti-affinity"
+
+// CreateServerGroup creates new server group with given policy and returns its ID
+func (d *Driver) CreateServerGroup(name, policy string) (string, error) {
+	if err := d.initComputeV2(); err != nil {
+		return "", err
+	}
+	group, err := d.client.CreateServerGroup(&servergroups.CreateOpts{
+		Name:     name,
+		Policies: []string{policy},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create server group: %s", logHttp500(err))
+	}
+	return group.ID, nil
+}
+
+// createServerGroup creates anti-affinity server group if it can't be found by name
+func (d *Driver) createServerGroup() error {
+	if d.ServerGroupID.Value != "" || d.ServerGroup == "" {
+		return nil
+	}
+	groupID, err := d.CreateServerGroup(d.ServerGroup, serverGroupPolicyAntiAffinity)
+	if err != nil {
+		return err
+	}
+	d.ServerGroupID = managedSting{Value: groupID, DriverManaged: true}
+	return nil
+}
+
+// deleteServerGroup deletes server group created by the driver if it has no members left
+func (d *Driver) deleteServerGroup() error {
+	if !d.ServerGroupID.DriverManaged || d.ServerGroupID.Value == "" {
+		return nil
+	}
+	if err := d.initComputeV2(); err != nil {
+		return err
+	}
+	group, err := servergroups.Get(d.compute, d.ServerGroupID.Value).Extract()
+	if err != nil {
+		if _, ok := err.(golangsdk.ErrDefault404); ok {
+			return nil
+		}
+		return fmt.Errorf("failed to get server group: %s", logHttp500(err))
+	}
+	if len(group.Members) > 0 {
+		return nil
+	}
+	if err := d.client.DeleteServerGroup(group.ID); err != nil {
+		return fmt.Errorf("failed to delete server group: %s", logHttp500(err))
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package opentelekomcloud
+
+import (
+	"fmt"
+	"strings"
+
+	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/common/tags"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/ecs/v1/cloudservers"
+)
+
+// resource types supporting tagging via network tag API
+const (
+	tagResourceVPC    = "vpcs"
+	tagResourceSubnet = "subnets"
+	tagResourceEIP    = "publicips"
+)
+
+// parseTags parses comma-separated list of `key=value` tags. Plain tags without `=`
+// are still supported for compatibility and are set as keys with empty value
+func parseTags(value string) ([]tags.ResourceTag, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var result []tags.ResourceTag
+	for _, tag := range strings.Split(value, ",") {
+		if tag != "" && !strings.Contains(tag, "=") {
+			result = append(result, tags.ResourceTag{Key: tag})
+			continue
+		}
+		key, val, err := splitKeyValue(tag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag: %s", err)
+		}
+		result = append(result, tags.ResourceTag{Key: key, Value: val})
+	}
+	return result, nil
+}
+
+// parseMetadata parses comma-separated list of `key=value` metadata items
+func parseMetadata(value string) (map[string]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	result := make(map[string]string)
+	for _, item := range strings.Split(value, ",") {
+		key, val, err := splitKeyValue(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metadata: %s", err)
+		}
+		result[key] = val
+	}
+	return result, nil
+}
+
+func splitKeyValue(pair string) (string, string, error) {
+	parts := strings.SplitN(pair, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("`%s` is not in `key=value` format", pair)
+	}
+	return parts[0], parts[1], nil
+}
+
+func (d *Driver) serverTags() []cloudservers.ServerTags {
+	if len(d.Tags) == 0 {
+		return nil
+	}
+	result := make([]cloudservers.ServerTags, len(d.Tags))
+	for i, tag := range d.Tags {
+		result[i] = cloudservers.ServerTags{Key: tag.Key, Value: tag.Value}
+	}
+	return result
+}
+
+// tagResource sets driver tags for given network resource,
+// used for resources not supporting tags on creation
+func (d *Driver) tagResource(resourceType, id string) error {
+	if len(d.Tags) == 0 {
+		return nil
+	}
+	if err := tags.Create(d.network, resourceType, id, d.Tags).ExtractErr(); err != nil {
+		return fmt.Errorf("failed to tag %s `%s`: %s", resourceType, id, logHttp500(err))
+	}
+	return nil
+}
+
+type tagFilter struct {
+	Key    string   `json:"key"`
+	Values []string `json:"values"`
+}
+
+// FindInstanceByTag returns ID of the instance having given tag,
+// empty string is returned if no instance found
+func (d *Driver) FindInstanceByTag(key, value string) (string, error) {
+	if err := d.initComputeV1(); err != nil {
+		return "", err
+	}
+	reqBody := map[string]interface{}{
+		"action": "filter",
+		"tags":   []tagFilter{{Key: key, Values: []string{value}}},
+	}
+	var body struct {
+		Resources []struct {
+			ID string `json:"resource_id"`
+		} `json:"resources"`
+	}
+	filterURL := d.ecs.ServiceURL("cloudservers", "resource_instances", "action")
+	_, err := d.ecs.Post(filterURL, reqBody, &body, &golangsdk.RequestOpts{OkCodes: []int{200}})
+	if err != nil {
+		return "", fmt.Errorf("failed to filter instances by tag: %s", logHttp500(err))
+	}
+	switch len(body.Resources) {
+	case 0:
+		return "", nil
+	case 1:
+		return body.Resources[0].ID, nil
+	default:
+		return "", fmt.Errorf("found %d instances with tag `%s=%s`, expected one", len(body.Resources), key, value)
+	}
+}
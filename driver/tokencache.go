@@ -0,0 +1,161 @@
+package opentelekomcloud
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/identity/v3/tokens"
+)
+
+// tokenExpiryMargin is the time before token expiration when the token is not reused anymore
+const tokenExpiryMargin = 5 * time.Minute
+
+// tokenSaltSize is a size of random salt used for hashing the credentials (in bytes)
+const tokenSaltSize = 16
+
+type cachedToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Salt      string    `json:"salt"`
+	// SecretHash is a salted hash of the credentials the token was issued for
+	SecretHash string `json:"secret_hash"`
+}
+
+// TokenCache stores scoped tokens in files, so they can be reused by different driver processes
+type TokenCache struct {
+	// Dir is a directory where token files are stored, it's accessible by the owner only
+	Dir string
+}
+
+// NewTokenCache creates token cache located in the user cache directory
+// or in the user-specific directory in the system temporary directory
+func NewTokenCache() *TokenCache {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return &TokenCache{Dir: filepath.Join(os.TempDir(), fmt.Sprintf("docker-machine-otc-tokens-%d", os.Getuid()))}
+	}
+	return &TokenCache{Dir: filepath.Join(base, "docker-machine-opentelekomcloud", "tokens")}
+}
+
+func (c *TokenCache) path(key string) string {
+	return filepath.Join(c.Dir, fmt.Sprintf("token-%s.json", key))
+}
+
+// secretHash returns hex-encoded hash of the secret with given salt
+func secretHash(salt []byte, secret string) string {
+	sum := sha256.Sum256(append(append([]byte{}, salt...), secret...))
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns cached token if it exists, is issued for the same secret and doesn't expire soon
+func (c *TokenCache) Get(key, secret string) (string, bool) {
+	data, err := ioutil.ReadFile(c.path(key))
+	if err != nil {
+		return "", false
+	}
+	token := cachedToken{}
+	if err := json.Unmarshal(data, &token); err != nil {
+		return "", false
+	}
+	salt, err := hex.DecodeString(token.Salt)
+	if err != nil || len(salt) == 0 {
+		return "", false
+	}
+	if subtle.ConstantTimeCompare([]byte(secretHash(salt, secret)), []byte(token.SecretHash)) != 1 {
+		return "", false
+	}
+	if token.Token == "" || time.Now().Add(tokenExpiryMargin).After(token.ExpiresAt) {
+		return "", false
+	}
+	return token.Token, true
+}
+
+// Put stores token issued for the secret to the cache, cache directory is created if it doesn't exist
+func (c *TokenCache) Put(key, secret, token string, expiresAt time.Time) error {
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return err
+	}
+	// MkdirAll doesn't change permissions of existing directory
+	if err := os.Chmod(c.Dir, 0700); err != nil {
+		return err
+	}
+	salt := make([]byte, tokenSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	data, err := json.Marshal(cachedToken{
+		Token:      token,
+		ExpiresAt:  expiresAt,
+		Salt:       hex.EncodeToString(salt),
+		SecretHash: secretHash(salt, secret),
+	})
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path(key), data, 0600)
+}
+
+// tokenCacheKey builds cache key unique for the user, project, domain and region,
+// secrets are not part of the key and are checked using salted hash stored with the token
+func tokenCacheKey(cloud *openstack.Cloud) string {
+	auth := cloud.AuthInfo
+	parts := []string{
+		auth.AuthURL, cloud.RegionName,
+		auth.DomainID, auth.DomainName, auth.ProjectID, auth.ProjectName,
+		auth.UserID, auth.Username,
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// canCacheToken reports if authentication with the cloud credentials produces a token which can be cached,
+// AK/SK authentication signs every request and doesn't use tokens at all
+func canCacheToken(cloud *openstack.Cloud) bool {
+	return cloud.AuthInfo.Token == "" && cloud.AuthInfo.AccessKey == ""
+}
+
+// authenticateCached authenticates using cached token when possible
+// and stores newly obtained token otherwise
+func (d *Driver) authenticateCached(cloud *openstack.Cloud) error {
+	cache := NewTokenCache()
+	key := tokenCacheKey(cloud)
+	secret := cloud.AuthInfo.Password
+	if token, ok := cache.Get(key, secret); ok {
+		tokenCloud := *cloud
+		tokenCloud.AuthType = "token"
+		tokenCloud.AuthInfo.Token = token
+		if client, err := d.newCloudClient(&tokenCloud); err == nil {
+			d.client = client
+			return nil
+		}
+		d.logger().Debugf("Cached token can't be used, re-authenticating")
+	}
+
+	client, err := d.newCloudClient(cloud)
+	if err != nil {
+		return err
+	}
+	d.client = client
+	identity, err := d.client.NewServiceClient("identity")
+	if err != nil {
+		return err
+	}
+	token, err := tokens.Get(identity, identity.Token()).ExtractToken()
+	if err != nil {
+		return err
+	}
+	if err := cache.Put(key, secret, token.ID, token.ExpiresAt); err != nil {
+		d.logger().Warnf("Failed to cache token: %s", err)
+	}
+	return nil
+}
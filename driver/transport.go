@@ -0,0 +1,128 @@
+package opentelekomcloud
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/opentelekomcloud-infra/crutch-house/services"
+	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack"
+)
+
+// baseTransport is the original default transport all API transports are derived from
+var baseTransport = http.DefaultTransport.(*http.Transport)
+
+// transportOpts contains HTTP transport settings for API calls
+type transportOpts struct {
+	Proxy    string
+	CACert   string
+	Insecure bool
+}
+
+// newTransport creates HTTP transport for API calls. Proxy is taken from
+// `HTTP_PROXY`, `HTTPS_PROXY` and `NO_PROXY` environment variables if not set explicitly.
+// Given CA certificates are added to the system pool
+func newTransport(opts transportOpts) (*http.Transport, error) {
+	transport := baseTransport.Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if opts.Proxy != "" {
+		proxyURL, err := url.Parse(opts.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid API proxy URL `%s`: %s", opts.Proxy, err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	if opts.CACert == "" && !opts.Insecure {
+		return transport, nil
+	}
+	tlsConfig := &tls.Config{InsecureSkipVerify: opts.Insecure}
+	if opts.CACert != "" {
+		pem, err := ioutil.ReadFile(opts.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate bundle: %s", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid certificates found in `%s`", opts.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+// configureTransport sets up transport used by provider clients of the driver.
+// Default transport is used if no transport settings are given
+func (d *Driver) configureTransport() error {
+	d.transport = nil
+	if d.APIProxy == "" && d.CACert == "" && !d.Insecure && d.APIRetries == 0 && !d.APIDebug {
+		return nil
+	}
+	var transport http.RoundTripper
+	transport, err := newTransport(transportOpts{
+		Proxy:    d.APIProxy,
+		CACert:   d.CACert,
+		Insecure: d.Insecure,
+	})
+	if err != nil {
+		return err
+	}
+	if d.APIDebug {
+		// every retry attempt is logged
+		transport = &debugTransport{base: transport, logger: d.logger()}
+	}
+	if d.APIRetries > 0 {
+		transport = &retryTransport{
+			base:       transport,
+			maxRetries: d.APIRetries,
+			baseDelay:  time.Duration(d.APIRetryDelay) * time.Second,
+			logger:     d.logger(),
+		}
+	}
+	d.transport = transport
+	return nil
+}
+
+// newProviderClient creates provider client using the driver transport and authenticates it,
+// so authentication requests go through the same transport as all other API calls
+func (d *Driver) newProviderClient(opts golangsdk.AuthOptionsProvider) (*golangsdk.ProviderClient, error) {
+	provider, err := openstack.NewClient(opts.GetIdentityEndpoint())
+	if err != nil {
+		return nil, err
+	}
+	if d.transport != nil {
+		provider.HTTPClient = http.Client{Transport: d.transport}
+	}
+	if err := openstack.Authenticate(provider, opts); err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+// newCloudClient creates client authenticated in the cloud. Provider client is created by the client on
+// authentication, so the authentication request itself is sent using default transport (respecting proxy
+// environment variables) and the driver transport is used by all following requests
+func (d *Driver) newCloudClient(cloud *openstack.Cloud) (services.Client, error) {
+	client := services.NewCloudClient(cloud)
+	if err := client.Authenticate(); err != nil {
+		return nil, err
+	}
+	if d.transport == nil {
+		return client, nil
+	}
+	sc, err := client.NewServiceClient("identity")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provider client: %s", logHttp500(err))
+	}
+	// all service clients of the client share the same provider client
+	sc.ProviderClient.HTTPClient = http.Client{Transport: d.transport}
+	return client, nil
+}
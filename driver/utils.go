@@ -1,34 +1,110 @@
 package opentelekomcloud
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/opentelekomcloud-infra/crutch-house/services"
 	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
 	"github.com/opentelekomcloud/gophertelekomcloud/openstack"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/extensions/security/rules"
 	"github.com/opentelekomcloud/gophertelekomcloud/openstack/utils"
 )
 
 const (
-	errorBothOptions     = "both %s and %s must be specified"
-	notFound             = "%s not found by name `%s`"
-	driverName           = "otc"
-	dockerPort           = 2376
-	defaultSecurityGroup = "docker-machine-grp"
-	defaultAZ            = "eu-de-01"
-	defaultFlavor        = "s2.large.2"
-	defaultImage         = "Standard_Ubuntu_20.04_latest"
-	defaultSSHUser       = "ubuntu"
-	defaultSSHPort       = 22
-	defaultRegion        = "eu-de"
-	defaultAuthURL       = "https://iam.eu-de.otc.t-systems.com/v3"
-	defaultVpcName       = "vpc-docker-machine"
-	defaultSubnetName    = "subnet-docker-machine"
-	defaultVolumeSize    = 40
-	defaultVolumeType    = "SSD"
+	errorBothOptions       = "both %s and %s must be specified"
+	errorExclusiveOptions  = "%s and %s can't be specified together"
+	notFound               = "%s not found by name `%s`"
+	notFoundByID           = "%s not found by ID `%s`"
+	driverName             = "otc"
+	dockerPort             = 2376
+	defaultSecurityGroup   = "docker-machine-grp"
+	defaultAZ              = "eu-de-01"
+	defaultFlavor          = "s2.large.2"
+	defaultImage           = "Standard_Ubuntu_20.04_latest"
+	defaultSSHUser         = "ubuntu"
+	defaultSSHPort         = 22
+	defaultRegion          = "eu-de"
+	defaultAuthURL         = "https://iam.eu-de.otc.t-systems.com/v3"
+	defaultVpcName         = "vpc-docker-machine"
+	defaultSubnetName      = "subnet-docker-machine"
+	defaultSubnetCIDR      = "192.168.0.0/24"
+	defaultVolumeSize      = 40
+	defaultVolumeType      = "SSD"
+	maxMetadataKeyLength   = 255
+	maxMetadataValueLength = 255
+	maxUserDataSize        = 32 * 1024
+	cidrAll                = "0.0.0.0/0"
+
+	instanceStatusVerifyResize = "VERIFY_RESIZE"
+	instanceStatusError        = "ERROR"
+	minBandwidthSize           = 1
+	maxBandwidthSize           = 2000
 )
 
+// regionInfo contains IAM endpoint and availability zone naming of the region
+type regionInfo struct {
+	AuthURL string
+	// ZonePattern matches names of availability zones of the region
+	ZonePattern *regexp.Regexp
+}
+
+// knownRegions contains regions supported by the driver
+var knownRegions = map[string]regionInfo{
+	"eu-de": {
+		AuthURL:     defaultAuthURL,
+		ZonePattern: regexp.MustCompile(`^eu-de-\d+$`),
+	},
+	"eu-nl": {
+		AuthURL:     "https://iam.eu-nl.otc.t-systems.com/v3",
+		ZonePattern: regexp.MustCompile(`^eu-nl-\d+$`),
+	},
+	"eu-ch2": {
+		AuthURL:     "https://iam-pub.eu-ch2.sc.otc.t-systems.com/v3",
+		ZonePattern: regexp.MustCompile(`^eu-ch2[a-z]$`),
+	},
+}
+
+var validVolumeTypes = []string{"SATA", "SAS", "SSD", "ESSD"}
+
+// volumeTypeESSD is the only volume type supporting provisioned IOPS
+const volumeTypeESSD = "ESSD"
+
+// getAvailability returns endpoint availability matching endpoint type, public by default
+func getAvailability(endpointType string) golangsdk.Availability {
+	for _, availability := range []golangsdk.Availability{
+		golangsdk.AvailabilityPublic,
+		golangsdk.AvailabilityInternal,
+		golangsdk.AvailabilityAdmin,
+	} {
+		if strings.HasPrefix(endpointType, string(availability)) {
+			return availability
+		}
+	}
+	return golangsdk.AvailabilityPublic
+}
+
+// providerClient returns authenticated provider client used for services not covered by the client
+func (d *Driver) providerClient() (*golangsdk.ProviderClient, error) {
+	if err := d.Authenticate(); err != nil {
+		return nil, err
+	}
+	sc, err := d.client.NewServiceClient("identity")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provider client: %s", logHttp500(err))
+	}
+	return sc.ProviderClient, nil
+}
+
 // logHttp500 appends error message with response 500 body
 func logHttp500(err error) error {
 	if e, ok := err.(golangsdk.ErrDefault500); ok {
@@ -40,7 +116,7 @@ func logHttp500(err error) error {
 // resolveIDs resolves name to IDs where possible
 func (d *Driver) resolveIDs() error {
 	if d.VpcID.Value == "" && d.VpcName != "" {
-		vpcID, err := d.client.FindVPC(d.VpcName)
+		vpcID, err := d.findVPC(d.VpcName)
 		if err != nil {
 			return fmt.Errorf("failed to find VPC by name: %s", logHttp500(err))
 		}
@@ -48,15 +124,20 @@ func (d *Driver) resolveIDs() error {
 	}
 
 	if d.SubnetID.Value == "" && d.SubnetName != "" {
-		subnetID, err := d.client.FindSubnet(d.VpcID.Value, d.SubnetName)
+		subnetID, err := d.findSubnet(d.VpcID.Value, d.SubnetName)
 		if err != nil {
 			return fmt.Errorf("failed to find subnet by name: %s", logHttp500(err))
 		}
+		if subnetID != "" {
+			if err := d.validateSubnet(subnetID); err != nil {
+				return err
+			}
+		}
 		d.SubnetID = managedSting{Value: subnetID}
 	}
 
 	if d.FlavorID == "" && d.FlavorName != "" {
-		flavorID, err := d.client.FindFlavor(d.FlavorName)
+		flavorID, err := d.findFlavor(d.FlavorName)
 		if err != nil {
 			return fmt.Errorf("fail when searching flavor by name: %s", logHttp500(err))
 		}
@@ -66,7 +147,7 @@ func (d *Driver) resolveIDs() error {
 		d.FlavorID = flavorID
 	}
 	if d.RootVolumeOpts.SourceID == "" && d.ImageName != "" {
-		imageID, err := d.client.FindImage(d.ImageName)
+		imageID, err := d.findImage(d.ImageName)
 		if err != nil {
 			return fmt.Errorf("failed to find image by name: %s", logHttp500(err))
 		}
@@ -75,33 +156,310 @@ func (d *Driver) resolveIDs() error {
 		}
 		d.RootVolumeOpts.SourceID = imageID
 	}
-	sgIDs, err := d.client.FindSecurityGroups(d.SecurityGroups)
+	sgIDs, err := d.findSecurityGroups(d.SecurityGroups)
 	if err != nil {
 		return fmt.Errorf("failed to resolve security group IDs: %s", logHttp500(err))
 	}
 	d.SecurityGroupIDs = sgIDs
 
-	if d.ServerGroupID == "" && d.ServerGroup != "" {
+	if d.ServerGroupID.Value == "" && d.ServerGroup != "" {
 		serverGroupID, err := d.client.FindServerGroup(d.ServerGroup)
 		if err != nil {
 			return fmt.Errorf("failed to resolve server group: %s", logHttp500(err))
 		}
-		d.ServerGroupID = serverGroupID
+		d.ServerGroupID = managedSting{Value: serverGroupID}
+	}
+
+	if d.AvailabilityZone == "" {
+		if err := d.chooseAvailabilityZone(); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// parseBoolOption parses value of boolean option having `true` default, so it can't be a bool flag
+func parseBoolOption(name, value string) (bool, error) {
+	result, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s value `%s`, must be `true` or `false`", name, value)
+	}
+	return result, nil
+}
+
+const (
+	minAdminPasswordLength = 8
+	maxAdminPasswordLength = 26
+
+	adminPasswordSpecialChars = "~!@#$%^&*()-_=+\\|[{}];:'\",<.>/?"
+)
+
+// validateAdminPassword checks that the password matches ECS password complexity rules:
+// 8-26 characters, at least three of uppercase letters, lowercase letters, digits and special
+// characters, not containing the user name or the reversed user name. The password is never
+// included into the error
+func validateAdminPassword(password, username string) error {
+	if len(password) < minAdminPasswordLength || len(password) > maxAdminPasswordLength {
+		return fmt.Errorf("admin password must be %d-%d characters long",
+			minAdminPasswordLength, maxAdminPasswordLength)
+	}
+	var upper, lower, digit, special bool
+	for _, c := range password {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			upper = true
+		case c >= 'a' && c <= 'z':
+			lower = true
+		case c >= '0' && c <= '9':
+			digit = true
+		case strings.ContainsRune(adminPasswordSpecialChars, c):
+			special = true
+		default:
+			return fmt.Errorf("admin password contains character which is not allowed, "+
+				"only letters, digits and `%s` can be used", adminPasswordSpecialChars)
+		}
+	}
+	kinds := 0
+	for _, present := range []bool{upper, lower, digit, special} {
+		if present {
+			kinds++
+		}
+	}
+	if kinds < 3 {
+		return fmt.Errorf("admin password must contain at least three of: " +
+			"uppercase letters, lowercase letters, digits, special characters")
+	}
+	lowerPassword := strings.ToLower(password)
+	for _, name := range []string{username, "root", "administrator"} {
+		if name == "" {
+			continue
+		}
+		name = strings.ToLower(name)
+		if strings.Contains(lowerPassword, name) || strings.Contains(lowerPassword, reverse(name)) {
+			return fmt.Errorf("admin password can't contain user name `%s` or reversed user name", name)
+		}
+	}
+	return nil
+}
+
+func reverse(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
+// passwordOnly reports if admin password is used without a key pair
+func (d *Driver) passwordOnly() bool {
+	return d.AdminPassword != "" && d.KeyPairName.Value == "" && !d.CloudGeneratedKey
+}
+
+// maxInstanceNameLength is a maximum length of ECS instance name
+const maxInstanceNameLength = 64
+
+// invalidInstanceNameChars matches characters not allowed in ECS instance name
+var invalidInstanceNameChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+// normalizeInstanceName replaces characters not allowed in ECS instance name with `-`
+// and truncates the name to the maximum length
+func normalizeInstanceName(name string) (string, error) {
+	normalized := invalidInstanceNameChars.ReplaceAllString(name, "-")
+	if len(normalized) > maxInstanceNameLength {
+		normalized = normalized[:maxInstanceNameLength]
+	}
+	if strings.Trim(normalized, "-_.") == "" {
+		return "", fmt.Errorf("machine name `%s` can't be used as instance name: it must contain letters or digits", name)
+	}
+	return normalized, nil
+}
+
+// instanceName returns name of the instance, machine name is used if normalized name is not set
+func (d *Driver) instanceName() string {
+	if d.InstanceName != "" {
+		return d.InstanceName
+	}
+	return d.MachineName
+}
+
+// validateInstanceName sets instance name matching ECS naming rules, warning is logged if the name
+// differs from the machine name
+func (d *Driver) validateInstanceName() error {
+	name, err := normalizeInstanceName(d.MachineName)
+	if err != nil {
+		return err
+	}
+	if name != d.MachineName {
+		d.logger().Warnf("Machine name `%s` doesn't match instance naming rules, instance is named `%s`", d.MachineName, name)
+		d.InstanceName = name
+	}
+	return nil
+}
+
+// validateBootFromImage checks that options supported only by ECS creation of volume-booted
+// instance are not used for the instance booted from the image
+func (d *Driver) validateBootFromImage() error {
+	switch {
+	case len(d.DataVolumes) > 0:
+		return fmt.Errorf(errorExclusiveOptions, "BootFromImage", "DataVolumes")
+	case d.KMSKeyID != "":
+		return fmt.Errorf(errorExclusiveOptions, "BootFromImage", "KMSKeyID")
+	case d.prePaid():
+		return fmt.Errorf(errorExclusiveOptions, "BootFromImage", "BillingMode")
+	case d.IPv6:
+		return fmt.Errorf(errorExclusiveOptions, "BootFromImage", "IPv6")
+	case d.DedicatedHostID != "":
+		return fmt.Errorf(errorExclusiveOptions, "BootFromImage", "DedicatedHostID")
+	case d.RootVolumeIOPS != 0:
+		return fmt.Errorf(errorExclusiveOptions, "BootFromImage", "RootVolumeIOPS")
+	case d.BindEIPOnCreate:
+		return fmt.Errorf(errorExclusiveOptions, "BootFromImage", "BindEIPOnCreate")
+	}
+	return nil
+}
+
+// validateRootVolumeIOPS checks that root volume IOPS are set only for ESSD volume
+func (d *Driver) validateRootVolumeIOPS() error {
+	switch {
+	case d.RootVolumeIOPS == 0:
+		return nil
+	case d.RootVolumeIOPS < 0:
+		return fmt.Errorf("root volume IOPS must be positive, got %d", d.RootVolumeIOPS)
+	case d.RootVolumeOpts.Type != volumeTypeESSD:
+		return fmt.Errorf("root volume IOPS can be set only for `%s` volume type, got `%s`",
+			volumeTypeESSD, d.RootVolumeOpts.Type)
+	case d.BootFromImage:
+		return fmt.Errorf(errorExclusiveOptions, "BootFromImage", "RootVolumeIOPS")
+	}
+	return nil
+}
+
+// uniqueName returns name made of the prefix, current time and random suffix, so names generated
+// by machines created in parallel don't collide
+func uniqueName(prefix string) (string, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate random name suffix: %s", err)
+	}
+	timestamp := strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 36)
+	return fmt.Sprintf("%s-%s-%s", prefix, timestamp, hex.EncodeToString(suffix)), nil
+}
+
+// validateVolumeType checks that volume type is one of the known types
+func validateVolumeType(volumeType string) error {
+	for _, known := range validVolumeTypes {
+		if volumeType == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid volume type `%s`, must be one of: %s", volumeType, strings.Join(validVolumeTypes, ", "))
+}
+
 func (d *Driver) checkConfig() error {
 	if (d.KeyPairName.Value != "" && d.PrivateKeyFile == "") || (d.KeyPairName.Value == "" && d.PrivateKeyFile != "") {
 		return fmt.Errorf(errorBothOptions, "KeyPairName", "PrivateKeyFile")
 	}
+	if d.CloudGeneratedKey && d.KeyPairName.Value != "" {
+		return fmt.Errorf(errorExclusiveOptions, "CloudGeneratedKey", "KeyPairName")
+	}
+	if d.skipEIPCreation && d.ElasticIP.Value != "" {
+		return fmt.Errorf(errorExclusiveOptions, "ElasticIP", "SkipFloatingIP")
+	}
+	if d.SharedBandwidthID != "" && (d.skipEIPCreation || d.ElasticIP.Value != "") {
+		return fmt.Errorf("shared bandwidth can be used only with elastic IP created by the driver")
+	}
+	if d.AvailabilityZone != "" && len(d.AvailabilityZones) > 0 {
+		return fmt.Errorf(errorExclusiveOptions, "AvailabilityZone", "AvailabilityZones")
+	}
+	if d.PeerVpcID == "" && (d.PeerProjectID != "" || len(d.PeerRoutes) > 0) {
+		return fmt.Errorf("peer project ID and peer routes require peer VPC ID")
+	}
+	if err := d.validateBilling(); err != nil {
+		return err
+	}
+	if err := d.validateRootVolumeIOPS(); err != nil {
+		return err
+	}
+	if d.BootFromImage {
+		if err := d.validateBootFromImage(); err != nil {
+			return err
+		}
+	}
+	if d.WaitTimeout <= 0 {
+		return fmt.Errorf("wait timeout must be positive, got %d", d.WaitTimeout)
+	}
+	if d.WaitInterval <= 0 || d.WaitInterval > d.WaitTimeout {
+		return fmt.Errorf("wait interval must be positive and not greater than wait timeout, got %d", d.WaitInterval)
+	}
+	if d.SSHGracePeriod < 0 || d.SSHGracePeriod >= d.WaitTimeout {
+		return fmt.Errorf("SSH grace period can't be negative and must be less than wait timeout, got %d", d.SSHGracePeriod)
+	}
+	if d.APIRetries < 0 || d.APIRetryDelay < 0 {
+		return fmt.Errorf("API retries and retry delay can't be negative")
+	}
+	if err := d.validateRegion(); err != nil {
+		return err
+	}
+	if err := validateEndpoint("compute", d.ComputeEndpoint); err != nil {
+		return err
+	}
+	if err := validateEndpoint("network", d.NetworkEndpoint); err != nil {
+		return err
+	}
+	if err := validateEndpoint("API proxy", d.APIProxy); err != nil {
+		return err
+	}
+	if (d.AgencyName == "") != (d.AgencyDomain == "") {
+		return fmt.Errorf(errorBothOptions, "AgencyName", "AgencyDomain")
+	}
 	if d.Cloud == "" &&
 		(d.Username == "" || d.Password == "") &&
 		d.Token == "" &&
 		(d.AccessKey == "" || d.SecretKey == "") {
 		return fmt.Errorf("at least one authorization method must be provided")
 	}
+	if _, _, err := net.ParseCIDR(d.SubnetCIDR); err != nil {
+		return fmt.Errorf("invalid subnet CIDR `%s`: %s", d.SubnetCIDR, err)
+	}
+	if d.PrivateIP != "" {
+		if ip := net.ParseIP(d.PrivateIP); ip == nil || ip.To4() == nil {
+			return fmt.Errorf("invalid private IP `%s`", d.PrivateIP)
+		}
+	}
+	if d.SubnetGateway != "" && net.ParseIP(d.SubnetGateway) == nil {
+		return fmt.Errorf("invalid subnet gateway IP `%s`", d.SubnetGateway)
+	}
+	for _, dns := range d.SubnetDNSServers {
+		if net.ParseIP(dns) == nil {
+			return fmt.Errorf("invalid DNS server IP `%s`", dns)
+		}
+	}
+	if d.RootVolumeOpts.Size <= 0 {
+		return fmt.Errorf("root volume size must be positive, got %d", d.RootVolumeOpts.Size)
+	}
+	if size := d.eipConfig.BandwidthSize; size < minBandwidthSize || size > maxBandwidthSize {
+		return fmt.Errorf("bandwidth size must be in range %d-%d, got %d", minBandwidthSize, maxBandwidthSize, size)
+	}
+	if shareType := d.eipConfig.BandwidthType; shareType != "PER" && shareType != "WHOLE" {
+		return fmt.Errorf("bandwidth share type must be one of `PER`, `WHOLE`, got `%s`", shareType)
+	}
+	for _, cidr := range d.SourceCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("invalid source CIDR `%s`: %s", cidr, err)
+		}
+	}
+	for key, value := range d.Metadata {
+		if len(key) > maxMetadataKeyLength {
+			return fmt.Errorf("metadata key `%s` exceeds %d characters", key, maxMetadataKeyLength)
+		}
+		if len(value) > maxMetadataValueLength {
+			return fmt.Errorf("metadata value of `%s` exceeds %d characters", key, maxMetadataValueLength)
+		}
+	}
+	if d.UseIPv6SSH && !d.IPv6 {
+		return fmt.Errorf("`-otc-use-ipv6-ssh` requires `-otc-ipv6` to be set")
+	}
 	if len(d.UserData) > 0 && d.UserDataFile != "" {
 		return fmt.Errorf("both `-otc-user-data` and `-otc-user-data-file` is defined")
 	}
@@ -152,3 +510,148 @@ func (d *Driver) getUserData() error {
 	d.UserData = userData
 	return nil
 }
+
+// setRegionDefaults derives authentication URL from the region if it's not set explicitly
+func (d *Driver) setRegionDefaults() {
+	if d.Region == "" || d.Region == defaultRegion {
+		return
+	}
+	region, ok := knownRegions[d.Region]
+	if ok && d.AuthURL == defaultAuthURL {
+		d.AuthURL = region.AuthURL
+	}
+}
+
+func (d *Driver) validateRegion() error {
+	if d.Region == "" {
+		return nil
+	}
+	region, ok := knownRegions[d.Region]
+	if !ok {
+		names := make([]string, 0, len(knownRegions))
+		for name := range knownRegions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown region `%s`, expected one of %s", d.Region, strings.Join(names, ", "))
+	}
+	for _, az := range append([]string{d.AvailabilityZone}, d.AvailabilityZones...) {
+		if az != "" && !region.ZonePattern.MatchString(az) {
+			return fmt.Errorf("availability zone `%s` doesn't belong to region `%s`", az, d.Region)
+		}
+	}
+	return nil
+}
+
+// validateUserData loads user data and checks it fits size limit
+func (d *Driver) validateUserData() error {
+	if err := d.getUserData(); err != nil {
+		return err
+	}
+	if len(d.UserData) > maxUserDataSize {
+		return fmt.Errorf("user data size is %d bytes, must not exceed %d bytes", len(d.UserData), maxUserDataSize)
+	}
+	return nil
+}
+
+// parseDataDisks parses comma-separated list of `type:size` data disk definitions
+func parseDataDisks(disks string) ([]services.DiskOpts, error) {
+	var result []services.DiskOpts
+	for _, disk := range strings.Split(disks, ",") {
+		parts := strings.Split(strings.TrimSpace(disk), ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid data disk definition `%s`, expected `type:size`", disk)
+		}
+		size, err := strconv.Atoi(parts[1])
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("invalid data disk size `%s`", parts[1])
+		}
+		result = append(result, services.DiskOpts{
+			Type: parts[0],
+			Size: size,
+		})
+	}
+	return result, nil
+}
+
+// cidrContains checks if `inner` network is completely inside of `outer` network
+func cidrContains(outer, inner *net.IPNet) bool {
+	outerSize, _ := outer.Mask.Size()
+	innerSize, _ := inner.Mask.Size()
+	return outer.Contains(inner.IP) && outerSize <= innerSize
+}
+
+// firstAddress returns first usable address of the network
+func firstAddress(network *net.IPNet) net.IP {
+	ip := make(net.IP, len(network.IP))
+	copy(ip, network.IP)
+	for i := len(ip) - 1; i >= 0; i-- {
+		ip[i]++
+		if ip[i] != 0 {
+			break
+		}
+	}
+	return ip
+}
+
+// parseEgressRules parses comma-separated list of `from[-to]:cidr` egress rule definitions
+func parseEgressRules(egress string) ([]secGroupRule, error) {
+	var result []secGroupRule
+	for _, def := range strings.Split(egress, ",") {
+		parts := strings.SplitN(strings.TrimSpace(def), ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid egress rule `%s`, expected `from[-to]:cidr`", def)
+		}
+		from, to, err := parsePortRange(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		if _, _, err := net.ParseCIDR(parts[1]); err != nil {
+			return nil, fmt.Errorf("invalid egress rule CIDR `%s`: %s", parts[1], err)
+		}
+		result = append(result, secGroupRule{Protocol: rules.ProtocolTCP, From: from, To: to, CIDR: parts[1]})
+	}
+	return result, nil
+}
+
+// parseOpenPorts parses comma-separated list of `[protocol:]from[-to]` port definitions
+func parseOpenPorts(ports string) ([]secGroupRule, error) {
+	var result []secGroupRule
+	for _, def := range strings.Split(ports, ",") {
+		def = strings.TrimSpace(def)
+		protocol := rules.ProtocolTCP
+		if parts := strings.SplitN(def, ":", 2); len(parts) == 2 {
+			protocol = rules.RuleProtocol(strings.ToLower(parts[0]))
+			def = parts[1]
+		}
+		if protocol != rules.ProtocolTCP && protocol != rules.ProtocolUDP {
+			return nil, fmt.Errorf("unsupported protocol `%s`, expected `tcp` or `udp`", protocol)
+		}
+		from, to, err := parsePortRange(def)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, secGroupRule{Protocol: protocol, From: from, To: to})
+	}
+	return result, nil
+}
+
+// parsePortRange parses `from[-to]` port range
+func parsePortRange(ports string) (int, int, error) {
+	bounds := strings.SplitN(ports, "-", 2)
+	from, err := strconv.Atoi(bounds[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid port `%s`", bounds[0])
+	}
+	to := from
+	if len(bounds) == 2 {
+		to, err = strconv.Atoi(bounds[1])
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid port `%s`", bounds[1])
+		}
+	}
+	if from < 1 || to > 65535 || from > to {
+		return 0, 0, fmt.Errorf("invalid port range `%s`", ports)
+	}
+	return from, to, nil
+}
@@ -0,0 +1,158 @@
+package opentelekomcloud
+
+import (
+	"fmt"
+
+	"github.com/opentelekomcloud-infra/crutch-house/services"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/blockstorage/v2/volumes"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/extensions/volumeattach"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/servers"
+)
+
+const (
+	volumeStatusAvailable = "available"
+	volumeStatusInUse     = "in-use"
+)
+
+// AttachVolume attaches existing volume to the instance and waits for the volume to be in use.
+// Device is selected automatically if empty
+func (d *Driver) AttachVolume(instanceID, volumeID, device string) error {
+	if err := d.initComputeV2(); err != nil {
+		return err
+	}
+	if err := d.initVolume(); err != nil {
+		return err
+	}
+	volume, err := volumes.Get(d.volume, volumeID).Extract()
+	if err != nil {
+		return fmt.Errorf("failed to get volume: %s", logHttp500(err))
+	}
+	for _, attachment := range volume.Attachments {
+		if attachment.ServerID == instanceID {
+			return nil
+		}
+		return fmt.Errorf("volume `%s` is already attached to instance `%s`", volumeID, attachment.ServerID)
+	}
+	if volume.Status != volumeStatusAvailable {
+		return fmt.Errorf("volume `%s` is in %s status", volumeID, volume.Status)
+	}
+	opts := volumeattach.CreateOpts{VolumeID: volumeID, Device: device}
+	if err := volumeattach.Create(d.compute, instanceID, opts).Err; err != nil {
+		return fmt.Errorf("failed to attach volume: %s", logHttp500(err))
+	}
+	if err := d.WaitForVolumeStatus(volumeID, volumeStatusInUse); err != nil {
+		return fmt.Errorf("failed to wait for volume attachment: %s", logHttp500(err))
+	}
+	return nil
+}
+
+// DetachVolume detaches volume from the instance and waits for the volume to be available,
+// volume not attached to the instance is ignored
+func (d *Driver) DetachVolume(instanceID, volumeID string) error {
+	if err := d.initComputeV2(); err != nil {
+		return err
+	}
+	if err := d.initVolume(); err != nil {
+		return err
+	}
+	volume, err := volumes.Get(d.volume, volumeID).Extract()
+	if err != nil {
+		return fmt.Errorf("failed to get volume: %s", logHttp500(err))
+	}
+	attached := false
+	for _, attachment := range volume.Attachments {
+		if attachment.ServerID == instanceID {
+			attached = true
+		}
+	}
+	if !attached {
+		return nil
+	}
+	if err := volumeattach.Delete(d.compute, instanceID, volumeID).ExtractErr(); err != nil {
+		return fmt.Errorf("failed to detach volume: %s", logHttp500(err))
+	}
+	if err := d.WaitForVolumeStatus(volumeID, volumeStatusAvailable); err != nil {
+		return fmt.Errorf("failed to wait for volume detachment: %s", logHttp500(err))
+	}
+	return nil
+}
+
+// validateAttachVolume checks that the volume to be attached exists and isn't used by other instance.
+// Machine is created in the volume availability zone if the zone is not configured
+func (d *Driver) validateAttachVolume() error {
+	if d.AttachVolumeID == "" {
+		return nil
+	}
+	if err := d.initVolume(); err != nil {
+		return err
+	}
+	volume, err := volumes.Get(d.volume, d.AttachVolumeID).Extract()
+	if err != nil {
+		return fmt.Errorf("failed to get volume: %s", logHttp500(err))
+	}
+	if len(volume.Attachments) > 0 {
+		return fmt.Errorf("volume `%s` is already attached to instance `%s`",
+			d.AttachVolumeID, volume.Attachments[0].ServerID)
+	}
+	if d.AvailabilityZone == "" && len(d.AvailabilityZones) == 0 {
+		d.AvailabilityZone = volume.AvailabilityZone
+		return nil
+	}
+	zones := d.AvailabilityZones
+	if d.AvailabilityZone != "" {
+		zones = []string{d.AvailabilityZone}
+	}
+	for _, az := range zones {
+		if az == volume.AvailabilityZone {
+			d.AvailabilityZone = az
+			return nil
+		}
+	}
+	return fmt.Errorf("volume `%s` is in availability zone `%s` not used for the machine",
+		d.AttachVolumeID, volume.AvailabilityZone)
+}
+
+// attachVolume attaches configured existing volume to the machine instance
+func (d *Driver) attachVolume() error {
+	if d.AttachVolumeID == "" {
+		return nil
+	}
+	return d.AttachVolume(d.InstanceID, d.AttachVolumeID, "")
+}
+
+// detachVolume detaches configured existing volume from the machine instance, the volume is kept
+func (d *Driver) detachVolume() error {
+	if d.AttachVolumeID == "" || d.InstanceID == "" {
+		return nil
+	}
+	return d.DetachVolume(d.InstanceID, d.AttachVolumeID)
+}
+
+// keepInstanceVolumes detaches all volumes from the instance, so they are not deleted together with it.
+// Root volume can be detached from stopped instance only, so running instance is stopped first
+func (d *Driver) keepInstanceVolumes() error {
+	instance, err := servers.Get(d.compute, d.InstanceID).Extract()
+	if err != nil {
+		return fmt.Errorf("failed to get instance: %s", logHttp500(err))
+	}
+	if instance.Status == services.InstanceStatusRunning {
+		if err := d.StopInstance(true); err != nil {
+			return err
+		}
+	}
+	pages, err := volumeattach.List(d.compute, d.InstanceID).AllPages()
+	if err != nil {
+		return fmt.Errorf("failed to list instance volumes: %s", logHttp500(err))
+	}
+	attachments, err := volumeattach.ExtractVolumeAttachments(pages)
+	if err != nil {
+		return fmt.Errorf("failed to extract instance volumes: %s", logHttp500(err))
+	}
+	for _, attachment := range attachments {
+		if err := d.DetachVolume(d.InstanceID, attachment.VolumeID); err != nil {
+			return err
+		}
+		d.logger().Infof("Volume `%s` is kept after machine removal", attachment.VolumeID)
+	}
+	return nil
+}
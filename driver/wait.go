@@ -0,0 +1,426 @@
+package opentelekomcloud
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	golangsdk "github.com/opentelekomcloud/gophertelekomcloud"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/blockstorage/v2/volumes"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/extensions/secgroups"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/servers"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/ecs/v1/cloudservers"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/imageservice/v2/images"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v1/bandwidths"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/extensions/natgateways"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/extensions/snatrules"
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/networking/v2/peerings"
+)
+
+const (
+	// defaultWaitTimeout is used for all waits if no timeout is configured (in seconds)
+	defaultWaitTimeout = 300
+	// defaultWaitInterval is used for all waits if no polling interval is configured (in seconds)
+	defaultWaitInterval = 1
+)
+
+// waitTimeout returns configured timeout of waiting for resource status (in seconds)
+func (d *Driver) waitTimeout() int {
+	if d.WaitTimeout > 0 {
+		return d.WaitTimeout
+	}
+	return defaultWaitTimeout
+}
+
+// waitInterval returns configured interval of polling resource status (in seconds)
+func (d *Driver) waitInterval() int {
+	if d.WaitInterval > 0 {
+		return d.WaitInterval
+	}
+	return defaultWaitInterval
+}
+
+// waitFor polls predicate with configured interval until it's satisfied,
+// returns an error or configured timeout is exceeded
+func (d *Driver) waitFor(predicate func() (bool, error)) error {
+	return waitForContext(context.Background(), d.waitTimeout(), d.waitInterval(), predicate)
+}
+
+// waitForContext polls predicate every `interval` seconds until it's satisfied, returns an error,
+// `timeout` seconds are exceeded or the context is done. Predicate is checked right away for the first time
+func waitForContext(ctx context.Context, timeout, interval int, predicate func() (bool, error)) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(time.Duration(timeout) * time.Second)
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+	for {
+		ok, err := predicate()
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout of %d seconds exceeded", timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// sshDialTimeout limits single attempt of connecting to SSH port
+const sshDialTimeout = 5 * time.Second
+
+// WaitForSSHReady waits for SSH port of the host to accept connections for `timeout` seconds
+func WaitForSSHReady(host string, port int, timeout int) error {
+	return waitForSSHReady(host, port, timeout, defaultWaitInterval)
+}
+
+// waitForSSHReady waits for SSH port of the host to accept connections checking it every `interval` seconds
+func waitForSSHReady(host string, port, timeout, interval int) error {
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	return waitForContext(context.Background(), timeout, interval, func() (bool, error) {
+		conn, err := net.DialTimeout("tcp", address, sshDialTimeout)
+		if err != nil {
+			return false, nil
+		}
+		_ = conn.Close()
+		return true, nil
+	})
+}
+
+// waitForSSH waits for the machine SSH port to be reachable, the check is skipped if bastion host is used.
+// If the port is not reachable within SSH grace period, the reason is logged before waiting further
+func (d *Driver) waitForSSH() error {
+	if d.BastionHost != "" {
+		return nil
+	}
+	ip, err := d.GetIP()
+	if err != nil {
+		return err
+	}
+	timeout := d.waitTimeout()
+	if d.SSHGracePeriod > 0 {
+		if err := waitForSSHReady(ip, d.SSHPort, d.SSHGracePeriod, d.waitInterval()); err == nil {
+			return nil
+		}
+		d.diagnoseSSHTimeout()
+		timeout -= d.SSHGracePeriod
+	}
+	if err := waitForSSHReady(ip, d.SSHPort, timeout, d.waitInterval()); err != nil {
+		return fmt.Errorf("failed to wait for SSH port to be reachable: %s", err)
+	}
+	return nil
+}
+
+// waitForInstanceStatus waits for instance to be in given status
+func (d *Driver) waitForInstanceStatus(instanceID, status string) error {
+	return d.WaitForInstanceStatusContext(context.Background(), instanceID, status)
+}
+
+// WaitForInstanceStatusContext waits for instance to be in given status,
+// waiting is aborted when the context is done
+func (d *Driver) WaitForInstanceStatusContext(ctx context.Context, instanceID, status string) error {
+	if err := d.initComputeV2(); err != nil {
+		return err
+	}
+	return waitForContext(ctx, d.waitTimeout(), d.waitInterval(), func() (bool, error) {
+		current, err := servers.Get(d.compute, instanceID).Extract()
+		if err != nil {
+			return false, wrapNotFound(err, ErrInstanceNotFound)
+		}
+		return current.Status == status, nil
+	})
+}
+
+// waitForImageStatus waits for image to be in given status
+func (d *Driver) waitForImageStatus(imageID string, status images.ImageStatus) error {
+	return d.waitFor(func() (bool, error) {
+		image, err := images.Get(d.image, imageID).Extract()
+		if err != nil {
+			return true, wrapNotFound(err, ErrImageNotFound)
+		}
+		if image.Status == images.ImageStatusKilled {
+			return true, fmt.Errorf("image `%s` is in killed status", imageID)
+		}
+		return image.Status == status, nil
+	})
+}
+
+// waitForJobSuccess waits for ECS job to succeed for `timeout` seconds
+func (d *Driver) waitForJobSuccess(ctx context.Context, jobID string, timeout int) error {
+	return waitForContext(ctx, timeout, d.waitInterval(), func() (bool, error) {
+		job := new(cloudservers.JobStatus)
+		if _, err := d.ecs.Get(d.ecs.ServiceURL("jobs", jobID), job, nil); err != nil {
+			return false, err
+		}
+		switch job.Status {
+		case "SUCCESS":
+			return true, nil
+		case "FAIL":
+			return false, fmt.Errorf("job failed with code %s: %s", job.ErrorCode, job.FailReason)
+		}
+		return false, nil
+	})
+}
+
+// waitForOrderSuccess waits for the order to be completed and returns ID of the ordered resource
+func (d *Driver) waitForOrderSuccess(ctx context.Context, orderID string, timeout int) (string, error) {
+	var resourceID string
+	orderURL := d.bss.ServiceURL(d.bss.DomainID, "common/order-mgr/orders-resource", orderID)
+	err := waitForContext(ctx, timeout, d.waitInterval(), func() (bool, error) {
+		order := new(cloudservers.OrderStatus)
+		if _, err := d.bss.Get(orderURL, order, nil); err != nil {
+			return false, err
+		}
+		if len(order.Resources) == 0 {
+			return false, nil
+		}
+		// resource ID is kept even if the order fails later, so the resource can be removed
+		if id := order.Resources[0].ResourceId; id != "" {
+			resourceID = id
+		}
+		switch order.Resources[0].Status {
+		case orderStatusSuccess:
+			return true, nil
+		case orderStatusFailed:
+			return false, fmt.Errorf("order `%s` failed with code %s: %s", orderID, order.ErrorCode, order.ErrorMsg)
+		}
+		return false, nil
+	})
+	return resourceID, err
+}
+
+// waitForVPCStatus waits for VPC to be in given status
+func (d *Driver) waitForVPCStatus(vpcID, status string) error {
+	return d.waitFor(func() (bool, error) {
+		vpc, err := d.client.GetVPCDetails(vpcID)
+		if err != nil {
+			return true, wrapNotFound(err, ErrVPCNotFound)
+		}
+		if vpc.Status == "ERROR" {
+			return true, fmt.Errorf("VPC `%s` is in error status", vpcID)
+		}
+		return vpc.Status == status, nil
+	})
+}
+
+// waitForSubnetStatus waits for subnet to be in given status for `timeout` seconds
+func (d *Driver) waitForSubnetStatus(subnetID, status string, timeout int) error {
+	return waitForContext(context.Background(), timeout, d.waitInterval(), func() (bool, error) {
+		subnet, err := d.client.GetSubnetStatus(subnetID)
+		if err != nil {
+			return true, wrapNotFound(err, ErrSubnetNotFound)
+		}
+		if subnet.Status == "ERROR" {
+			return true, fmt.Errorf("subnet `%s` is in error status", subnetID)
+		}
+		return subnet.Status == status, nil
+	})
+}
+
+// WaitForVolumeStatus waits for volume to be in given status (e.g. `available` or `in-use`),
+// empty status waits for the volume to be deleted. ErrVolumeNotFound is returned if the volume
+// doesn't exist while waiting for the status
+func (d *Driver) WaitForVolumeStatus(volumeID, status string) error {
+	if err := d.initVolume(); err != nil {
+		return err
+	}
+	return d.waitFor(func() (bool, error) {
+		volume, err := volumes.Get(d.volume, volumeID).Extract()
+		if err != nil {
+			if _, ok := err.(golangsdk.ErrDefault404); ok && status == "" {
+				return true, nil
+			}
+			return true, wrapNotFound(err, ErrVolumeNotFound)
+		}
+		if volume.Status == "error" || volume.Status == "error_deleting" {
+			return true, fmt.Errorf("volume `%s` is in %s status", volumeID, volume.Status)
+		}
+		return volume.Status == status, nil
+	})
+}
+
+// waitForEIPActive waits for elastic IP to become usable
+func (d *Driver) waitForEIPActive(eipID string) error {
+	return d.waitFor(func() (bool, error) {
+		status, err := d.client.GetEIPStatus(eipID)
+		if err != nil {
+			return true, err
+		}
+		return status == "ACTIVE" || status == "DOWN", nil
+	})
+}
+
+// waitForFloatingIPBinding waits for floating IP to be bound to the instance or unbound from it
+func (d *Driver) waitForFloatingIPBinding(ip, instanceID string, bound bool) error {
+	return d.waitFor(func() (bool, error) {
+		addresses, err := d.instanceAddresses(instanceID)
+		if err != nil {
+			return true, err
+		}
+		found := false
+		for _, addr := range addresses {
+			if addr.Type == addressTypeFloating && addr.Address == ip {
+				found = true
+			}
+		}
+		return found == bound, nil
+	})
+}
+
+// waitForEIPUnbound waits for elastic IP with given address to be unbound from any port
+func (d *Driver) waitForEIPUnbound(address string) error {
+	return d.waitFor(func() (bool, error) {
+		eip, err := d.findElasticIP(address)
+		if err != nil {
+			return true, err
+		}
+		return eip == nil || eip.PortID == "", nil
+	})
+}
+
+// waitForEIPDeleted waits for elastic IP with given address to be released
+func (d *Driver) waitForEIPDeleted(address string) error {
+	if err := d.initNetwork(); err != nil {
+		return err
+	}
+	return d.waitFor(func() (bool, error) {
+		eip, err := d.findElasticIP(address)
+		if err != nil {
+			return true, err
+		}
+		return eip == nil, nil
+	})
+}
+
+// waitForGroupDeleted waits for security group to be deleted
+func (d *Driver) waitForGroupDeleted(securityGroupID string) error {
+	return d.waitFor(func() (bool, error) {
+		err := secgroups.Get(d.compute, securityGroupID).Err
+		if err == nil {
+			return false, nil
+		}
+		if _, ok := err.(golangsdk.ErrDefault404); ok {
+			return true, nil
+		}
+		return true, err
+	})
+}
+
+// WaitForNatGatewayStatus waits for NAT gateway to be in given status
+func (d *Driver) WaitForNatGatewayStatus(gatewayID, status string) error {
+	if err := d.initNat(); err != nil {
+		return err
+	}
+	return d.waitFor(func() (bool, error) {
+		gateway, err := natgateways.Get(d.nat, gatewayID).Extract()
+		if err != nil {
+			return true, wrapNotFound(err, ErrNatGatewayNotFound)
+		}
+		if gateway.Status == "ERROR" {
+			return true, fmt.Errorf("NAT gateway `%s` is in error status", gatewayID)
+		}
+		return gateway.Status == status, nil
+	})
+}
+
+// WaitForNatGatewayDeleted waits for NAT gateway to be deleted
+func (d *Driver) WaitForNatGatewayDeleted(gatewayID string) error {
+	if err := d.initNat(); err != nil {
+		return err
+	}
+	return d.waitFor(func() (bool, error) {
+		err := natgateways.Get(d.nat, gatewayID).Err
+		if err == nil {
+			return false, nil
+		}
+		if _, ok := err.(golangsdk.ErrDefault404); ok {
+			return true, nil
+		}
+		return true, err
+	})
+}
+
+// WaitForSnatRuleStatus waits for SNAT rule to be in given status
+func (d *Driver) WaitForSnatRuleStatus(ruleID, status string) error {
+	if err := d.initNat(); err != nil {
+		return err
+	}
+	return d.waitFor(func() (bool, error) {
+		rule, err := snatrules.Get(d.nat, ruleID).Extract()
+		if err != nil {
+			return true, wrapNotFound(err, ErrSnatRuleNotFound)
+		}
+		if rule.Status == "ERROR" {
+			return true, fmt.Errorf("SNAT rule `%s` is in error status", ruleID)
+		}
+		return rule.Status == status, nil
+	})
+}
+
+// WaitForSnatRuleDeleted waits for SNAT rule to be deleted
+func (d *Driver) WaitForSnatRuleDeleted(ruleID string) error {
+	if err := d.initNat(); err != nil {
+		return err
+	}
+	return d.waitFor(func() (bool, error) {
+		err := snatrules.Get(d.nat, ruleID).Err
+		if err == nil {
+			return false, nil
+		}
+		if _, ok := err.(golangsdk.ErrDefault404); ok {
+			return true, nil
+		}
+		return true, err
+	})
+}
+
+// WaitForPeeringStatus waits for VPC peering to be in given status
+func (d *Driver) WaitForPeeringStatus(peeringID, status string) error {
+	return d.waitFor(func() (bool, error) {
+		peering, err := peerings.Get(d.network, peeringID).Extract()
+		if err != nil {
+			return true, wrapNotFound(err, ErrPeeringNotFound)
+		}
+		switch peering.Status {
+		case "REJECTED", "EXPIRED":
+			return true, fmt.Errorf("VPC peering `%s` is in %s status", peeringID, strings.ToLower(peering.Status))
+		}
+		return peering.Status == status, nil
+	})
+}
+
+// WaitForPeeringDeleted waits for VPC peering to be deleted
+func (d *Driver) WaitForPeeringDeleted(peeringID string) error {
+	return d.waitFor(func() (bool, error) {
+		err := peerings.Get(d.network, peeringID).Err
+		if err == nil {
+			return false, nil
+		}
+		if _, ok := err.(golangsdk.ErrDefault404); ok {
+			return true, nil
+		}
+		return true, err
+	})
+}
+
+// WaitForSharedBandwidthStatus waits for shared bandwidth to be in given status
+func (d *Driver) WaitForSharedBandwidthStatus(bandwidthID, status string) error {
+	return d.waitFor(func() (bool, error) {
+		bandwidth, err := bandwidths.Get(d.vpc, bandwidthID).Extract()
+		if err != nil {
+			return true, wrapNotFound(err, ErrSharedBandwidthNotFound)
+		}
+		return bandwidth.Status == status, nil
+	})
+}
@@ -0,0 +1,171 @@
+package opentelekomcloud
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	"github.com/opentelekomcloud/gophertelekomcloud/openstack/compute/v2/extensions/availabilityzones"
+)
+
+// ListAvailabilityZones returns names of available zones sorted by name
+func (d *Driver) ListAvailabilityZones() ([]string, error) {
+	if err := d.initComputeV2(); err != nil {
+		return nil, err
+	}
+	pages, err := availabilityzones.List(d.compute).AllPages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list availability zones: %s", logHttp500(err))
+	}
+	zones, err := availabilityzones.ExtractAvailabilityZones(pages)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract availability zones: %s", logHttp500(err))
+	}
+	var names []string
+	for _, zone := range zones {
+		if zone.ZoneState.Available {
+			names = append(names, zone.ZoneName)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// flavorAvailable reports if the instance flavor is available in the given zone
+func (d *Driver) flavorAvailable(az string) (bool, error) {
+	flavors, err := d.ListFlavors(az)
+	if err != nil {
+		return false, err
+	}
+	for _, flavor := range flavors {
+		if flavor.ID == d.FlavorID || (d.FlavorID == "" && flavor.Name == d.FlavorName) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// flavorInZone reports if the flavor with given ID is available in the zone
+func (d *Driver) flavorInZone(flavorID, az string) (bool, error) {
+	flavors, err := d.ListFlavors(az)
+	if err != nil {
+		return false, err
+	}
+	for _, flavor := range flavors {
+		if flavor.ID == flavorID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ListFlavorAZs returns names of available zones where flavor with given ID or name is available
+func (d *Driver) ListFlavorAZs(flavor string) ([]string, error) {
+	zones, err := d.ListAvailabilityZones()
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	for _, az := range zones {
+		flavors, err := d.ListFlavors(az)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range flavors {
+			if f.ID == flavor || f.Name == flavor {
+				result = append(result, az)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// validateFlavorZones checks that the flavor is available in configured availability zones
+func (d *Driver) validateFlavorZones() error {
+	zones := d.AvailabilityZones
+	if d.AvailabilityZone != "" {
+		zones = []string{d.AvailabilityZone}
+	}
+	if len(zones) == 0 {
+		// zone is selected by the flavor availability
+		return nil
+	}
+	flavorID := d.FlavorID
+	if flavorID == "" {
+		id, err := d.findFlavor(d.FlavorName)
+		if err != nil {
+			return fmt.Errorf("fail when searching flavor by name: %s", logHttp500(err))
+		}
+		if id == "" {
+			return fmt.Errorf(notFound, "flavor", d.FlavorName)
+		}
+		flavorID = id
+	}
+	available, err := d.ListFlavorAZs(flavorID)
+	if err != nil {
+		return err
+	}
+	availableSet := make(map[string]bool, len(available))
+	for _, az := range available {
+		availableSet[az] = true
+	}
+	for _, az := range zones {
+		if !availableSet[az] {
+			return fmt.Errorf("flavor `%s` is not available in availability zone `%s`, available zones: [%s]",
+				d.flavorRef(), az, strings.Join(available, ", "))
+		}
+	}
+	return nil
+}
+
+// selectAvailabilityZone sets the availability zone to the first zone (by name)
+// where the instance flavor is available
+func (d *Driver) selectAvailabilityZone() error {
+	zones, err := d.ListAvailabilityZones()
+	if err != nil {
+		return err
+	}
+	for _, az := range zones {
+		available, err := d.flavorAvailable(az)
+		if err != nil {
+			return err
+		}
+		if available {
+			d.AvailabilityZone = az
+			d.logger().Infof("Availability zone `%s` is selected for the machine", az)
+			return nil
+		}
+	}
+	return fmt.Errorf("flavor `%s` is not available in any availability zone", d.flavorRef())
+}
+
+// flavorRef returns resolved flavor ID or flavor name if the ID is not resolved yet
+func (d *Driver) flavorRef() string {
+	if d.FlavorID != "" {
+		return d.FlavorID
+	}
+	return d.FlavorName
+}
+
+// zoneByName picks one of the zones by hash of the machine name, so machines
+// of a fleet are spread evenly between the zones. Order of the zones doesn't matter
+func zoneByName(machineName string, zones []string) string {
+	sorted := append([]string{}, zones...)
+	sort.Strings(sorted)
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(machineName))
+	return sorted[h.Sum32()%uint32(len(sorted))]
+}
+
+// chooseAvailabilityZone sets the availability zone from the configured zones list
+// or selects it automatically if the list is empty
+func (d *Driver) chooseAvailabilityZone() error {
+	if len(d.AvailabilityZones) == 0 {
+		return d.selectAvailabilityZone()
+	}
+	d.AvailabilityZone = zoneByName(d.MachineName, d.AvailabilityZones)
+	d.logger().Infof("Availability zone `%s` is selected for the machine", d.AvailabilityZone)
+	return nil
+}
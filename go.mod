@@ -10,4 +10,5 @@ require (
 	github.com/opentelekomcloud-infra/crutch-house v0.3.0
 	github.com/opentelekomcloud/gophertelekomcloud v0.2.6
 	github.com/stretchr/testify v1.7.0
+	golang.org/x/crypto v0.0.0-20210220033148-5ea612d1eb83
 )